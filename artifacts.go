@@ -0,0 +1,229 @@
+package sls
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const artifactsOpt = "artifacts"
+
+func (w *Wrapper) artifactsRoot() string {
+	root := w.yamlDirPath
+	if w.ArtifactsDir != "" {
+		root = w.ArtifactsDir
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(w.yamlDirPath, root)
+		}
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return root
+	}
+	return abs
+}
+
+func (w *Wrapper) outputPath(platform string, elem ...string) string {
+	return filepath.Join(append([]string{w.artifactsRoot(), platform}, elem...)...)
+}
+
+// package references in serverless.yml may point into the artifacts dir with ${opt:artifacts}, when ArtifactsDir is
+// set relative references are resolved against it as well, since that's where the builders write
+func (w *Wrapper) artifactRef(ref string) string {
+	ref = strings.Replace(ref, "${opt:"+artifactsOpt+"}", w.artifactsRoot(), -1)
+	ref = strings.Replace(ref, "${opt:suffix}", w.suffix, -1)
+	if !filepath.IsAbs(ref) {
+		ref = filepath.Join(w.artifactsRoot(), ref)
+	}
+	return filepath.Clean(ref)
+}
+
+// packageConfigName is the config generated for sls runs whose package references were rewritten
+func (w *Wrapper) packageConfigName() string {
+	return "serverless-" + w.instanceKey() + "-package.yml"
+}
+
+// functionArtifacts maps the functions built into artifacts of their own, such as node bundles, to those artifacts,
+// leaving out the functions whose package.artifact already points at them
+func (w *Wrapper) functionArtifacts(results []BuildResult) map[string]string {
+	artifacts := make(map[string]string)
+	for _, r := range results {
+		if r.Artifact == "" {
+			continue
+		}
+		for _, key := range r.Functions {
+			f, ok := w.stack.Functions[key]
+			if ok && (f.Package.Artifact == "" || w.artifactRef(f.Package.Artifact) != r.Artifact) {
+				artifacts[key] = r.Artifact
+			}
+		}
+	}
+	return artifacts
+}
+
+// layerArtifacts maps the layers built into artifacts their package.artifact doesn't point at, such as layers with
+// python requirements, to those artifacts
+func (w *Wrapper) layerArtifacts(results []BuildResult) map[string]string {
+	artifacts := make(map[string]string)
+	for _, r := range results {
+		if r.Artifact == "" || !strings.HasPrefix(r.Runtime, "layer:") {
+			continue
+		}
+		name := strings.TrimPrefix(r.Runtime, "layer:")
+		l, ok := w.stack.Layers[name]
+		if ok && (l.Package.Artifact == "" || w.artifactRef(l.Package.Artifact) != r.Artifact) {
+			artifacts[name] = r.Artifact
+		}
+	}
+	return artifacts
+}
+
+// movedRefs maps the functions or layers whose package.artifact is relative to the source tree to where it's built
+// when ArtifactsDir is set
+func (w *Wrapper) movedRefs(packages map[string]PackageMeta) map[string]string {
+	refs := make(map[string]string)
+	if w.ArtifactsDir == "" {
+		return refs
+	}
+	for key, pkg := range packages {
+		if pkg.Artifact != "" && !strings.Contains(pkg.Artifact, "${opt:"+artifactsOpt+"}") && !filepath.IsAbs(pkg.Artifact) {
+			refs[key] = w.artifactRef(pkg.Artifact)
+		}
+	}
+	return refs
+}
+
+// setPackageArtifacts points the package.artifact of the section's entries at refs
+func setPackageArtifacts(config yaml.MapSlice, section string, refs map[string]string) (yaml.MapSlice, error) {
+	if len(refs) == 0 {
+		return config, nil
+	}
+	i := mapSliceIndex(config, section)
+	if i < 0 {
+		return config, nil
+	}
+	entries, ok := config[i].Value.(yaml.MapSlice)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s section", section)
+	}
+
+	keys := make([]string, 0, len(refs))
+	for key := range refs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	entries = append(yaml.MapSlice{}, entries...)
+	for _, key := range keys {
+		j := mapSliceIndex(entries, key)
+		if j < 0 {
+			continue
+		}
+		entry, _ := entries[j].Value.(yaml.MapSlice)
+		var pkg yaml.MapSlice
+		if k := mapSliceIndex(entry, "package"); k >= 0 {
+			pkg, _ = entry[k].Value.(yaml.MapSlice)
+		}
+		pkg = setMapSliceItem(append(yaml.MapSlice{}, pkg...), "artifact", refs[key])
+		entries[j].Value = setMapSliceItem(append(yaml.MapSlice{}, entry...), "package", pkg)
+	}
+	config[i].Value = entries
+	return config, nil
+}
+
+// packaged returns a wrapper running sls against a generated config whose package references point at the built
+// artifacts, functions built into artifacts of their own get them as package.artifact and references relative to the
+// source tree are moved into ArtifactsDir, it's w itself when nothing needs rewriting, done deletes the generated
+// config
+func (w *Wrapper) packaged(results []BuildResult) (*Wrapper, func(), error) {
+	functionPackages := make(map[string]PackageMeta, len(w.stack.Functions))
+	for key, f := range w.stack.Functions {
+		functionPackages[key] = f.Package
+	}
+	layerPackages := make(map[string]PackageMeta, len(w.stack.Layers))
+	for key, l := range w.stack.Layers {
+		layerPackages[key] = l.Package
+	}
+	functionRefs := w.movedRefs(functionPackages)
+	for key, artifact := range w.functionArtifacts(results) {
+		functionRefs[key] = artifact
+	}
+	layerRefs := w.movedRefs(layerPackages)
+	for key, artifact := range w.layerArtifacts(results) {
+		layerRefs[key] = artifact
+	}
+	if len(functionRefs) == 0 && len(layerRefs) == 0 {
+		return w, func() {}, nil
+	}
+
+	yamlData, err := ioutil.ReadFile(w.configPath())
+	if err != nil {
+		return nil, nil, err
+	}
+	var config yaml.MapSlice
+	err = yaml.Unmarshal(yamlData, &config)
+	if err != nil {
+		return nil, nil, err
+	}
+	config, err = setPackageArtifacts(config, "functions", functionRefs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", w.configName, err)
+	}
+	config, err = setPackageArtifacts(config, "layers", layerRefs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", w.configName, err)
+	}
+
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	path := filepath.Join(w.yamlDirPath, w.packageConfigName())
+	err = ioutil.WriteFile(path, out, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+	done := func() { os.Remove(path) }
+
+	clone := *w
+	clone.configName = w.packageConfigName()
+	other, err := clone.WithSuffix(w.suffix)
+	if err != nil {
+		done()
+		return nil, nil, err
+	}
+	return other, done, nil
+}
+
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(dst), 0755)
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		return err
+	}
+	return out.Close()
+}
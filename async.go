@@ -0,0 +1,203 @@
+package sls
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAsyncTimeout = 5 * time.Minute
+	asyncPollInterval   = 5 * time.Second
+)
+
+type AsyncOptions struct {
+	// Queue is the url of an sqs queue the function has as its on success or on failure destination, when empty the
+	// result is correlated from the function's logs by the invocation's request id
+	Queue   string
+	Timeout time.Duration
+}
+
+type AsyncResult struct {
+	RequestID string
+	// Condition, Response and Error are only known from a destination record, the condition is Success,
+	// RetriesExhausted or EventAgeExceeded
+	Condition string
+	Response  []byte
+	Error     *FunctionError
+	Logs      string
+}
+
+type logEvent struct {
+	LogStreamName string
+	Timestamp     int64
+	Message       string
+	EventId       string
+}
+
+type destinationRecord struct {
+	RequestContext struct {
+		RequestId   string
+		FunctionArn string
+		Condition   string
+	}
+	RequestPayload  json.RawMessage
+	ResponsePayload json.RawMessage
+}
+
+func jsonEqual(a, b []byte) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func unixMillis(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
+
+// filterLogEvents lists the function's log events since the given time, args are passed to filter-log-events
+func (w *Wrapper) filterLogEvents(ctx context.Context, name string, since time.Time, args ...string) ([]logEvent, error) {
+	var resp struct {
+		Events []logEvent
+	}
+	args = append([]string{"logs", "filter-log-events", "--log-group-name", "/aws/lambda/" + name,
+		"--start-time", unixMillis(since)}, args...)
+	err := w.awsCmd(ctx, &resp, args...)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Events, nil
+}
+
+// asyncResultFromLogs returns the invocation of the request id once its REPORT line is logged
+func asyncResultFromLogs(events []logEvent, requestID string) *AsyncResult {
+	var result *AsyncResult
+	stream := ""
+	var lines []string
+	for _, e := range events {
+		if result == nil {
+			if strings.HasPrefix(e.Message, "START RequestId: "+requestID+" ") {
+				result = &AsyncResult{RequestID: requestID}
+				stream = e.LogStreamName
+			}
+			continue
+		}
+		if e.LogStreamName != stream {
+			continue
+		}
+		if strings.HasPrefix(e.Message, "REPORT RequestId: "+result.RequestID) {
+			result.Logs = strings.Join(lines, "\n")
+			return result
+		}
+		if !strings.HasPrefix(e.Message, "END RequestId: ") {
+			lines = append(lines, strings.TrimRight(e.Message, "\n"))
+		}
+	}
+	return nil
+}
+
+// asyncResultFromQueue receives the queue's destination records until one is for the payload sent to the function,
+// which is deleted, the other records are left on the queue
+func (w *Wrapper) asyncResultFromQueue(ctx context.Context, queue string, name string, payload []byte) (*AsyncResult, error) {
+	var resp struct {
+		Messages []struct {
+			ReceiptHandle string
+			Body          string
+		}
+	}
+	err := w.awsCmd(ctx, &resp, "sqs", "receive-message", "--queue-url", queue,
+		"--max-number-of-messages", "10", "--wait-time-seconds", "5")
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range resp.Messages {
+		var record destinationRecord
+		if json.Unmarshal([]byte(m.Body), &record) != nil {
+			continue
+		}
+		if !strings.Contains(record.RequestContext.FunctionArn, ":function:"+name) || !jsonEqual(record.RequestPayload, payload) {
+			continue
+		}
+		err = w.awsCmd(ctx, nil, "sqs", "delete-message", "--queue-url", queue, "--receipt-handle", m.ReceiptHandle)
+		if err != nil {
+			return nil, err
+		}
+		result := &AsyncResult{
+			RequestID: record.RequestContext.RequestId,
+			Condition: record.RequestContext.Condition,
+			Response:  record.ResponsePayload,
+		}
+		result.Error = parseFunctionError(record.ResponsePayload)
+		return result, nil
+	}
+	return nil, nil
+}
+
+// InvokeAsync queues an event invocation of the function, with opts it waits for the invocation's result, a failed
+// invocation is returned alongside its *FunctionError
+func (w *Wrapper) InvokeAsync(ctx context.Context, key string, payload []byte, opts *AsyncOptions) (*AsyncResult, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	name := w.functionName(key)
+	// a minute of slack for clock skew with CloudWatch
+	start := time.Now().Add(-time.Minute)
+	out, err := w.lambdaInvoke(ctx, name, payload, "Event", false)
+	if err != nil {
+		return nil, err
+	}
+	if out.StatusCode != 202 {
+		return nil, fmt.Errorf("async invocation of %s returned status %d", name, out.StatusCode)
+	}
+	if opts == nil {
+		return &AsyncResult{RequestID: out.RequestID}, nil
+	}
+	if opts.Queue == "" && out.RequestID == "" {
+		return nil, fmt.Errorf("async invocation of %s returned no request id to find its logs by", name)
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = defaultAsyncTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for ctx.Err() == nil {
+		var result *AsyncResult
+		if opts.Queue != "" {
+			result, err = w.asyncResultFromQueue(ctx, opts.Queue, name, payload)
+		} else {
+			var events []logEvent
+			events, err = w.filterLogEvents(ctx, name, start)
+			if err == nil {
+				result = asyncResultFromLogs(events, out.RequestID)
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil && !isAwsNotFound(err) {
+			return nil, err
+		}
+		if result != nil {
+			if result.Error != nil {
+				return result, result.Error
+			}
+			return result, nil
+		}
+		if opts.Queue == "" {
+			sleepContext(ctx, asyncPollInterval)
+		}
+	}
+	if ctx.Err() == context.Canceled {
+		return nil, ctx.Err()
+	}
+	return nil, fmt.Errorf("no result for the async invocation of %s after %v", name, timeout)
+}
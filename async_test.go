@@ -0,0 +1,31 @@
+package sls
+
+import "testing"
+
+func TestAsyncResultFromLogsMatchesRequestID(t *testing.T) {
+	events := []logEvent{
+		{LogStreamName: "a", Message: "START RequestId: 11111111-aaaa Version: $LATEST\n"},
+		{LogStreamName: "b", Message: "START RequestId: 22222222-bbbb Version: $LATEST\n"},
+		{LogStreamName: "a", Message: "2021-03-04T10:15:02.117Z\t11111111-aaaa\tINFO\tother invocation\n"},
+		{LogStreamName: "b", Message: "2021-03-04T10:15:02.120Z\t22222222-bbbb\tINFO\tours\n"},
+		{LogStreamName: "a", Message: "END RequestId: 11111111-aaaa\n"},
+		{LogStreamName: "a", Message: "REPORT RequestId: 11111111-aaaa\tDuration: 1.00 ms\n"},
+		{LogStreamName: "b", Message: "END RequestId: 22222222-bbbb\n"},
+	}
+
+	if result := asyncResultFromLogs(events, "22222222-bbbb"); result != nil {
+		t.Fatalf("expected no result before the REPORT line, got %+v", result)
+	}
+
+	events = append(events, logEvent{LogStreamName: "b", Message: "REPORT RequestId: 22222222-bbbb\tDuration: 2.00 ms\n"})
+	result := asyncResultFromLogs(events, "22222222-bbbb")
+	if result == nil {
+		t.Fatal("expected the invocation's result")
+	}
+	if result.RequestID != "22222222-bbbb" {
+		t.Errorf("expected request id 22222222-bbbb, got %s", result.RequestID)
+	}
+	if result.Logs != "2021-03-04T10:15:02.120Z\t22222222-bbbb\tINFO\tours" {
+		t.Errorf("unexpected logs %q", result.Logs)
+	}
+}
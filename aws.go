@@ -0,0 +1,107 @@
+package sls
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+type awsError struct {
+	args   []string
+	err    error
+	stderr string
+}
+
+func (e *awsError) Error() string {
+	return fmt.Sprintf("aws %s: %v: %s", strings.Join(e.args, " "), e.err, e.stderr)
+}
+
+func isAwsNotFound(err error) bool {
+	if lambdaErr, ok := err.(*lambdaError); ok {
+		return lambdaErr.StatusCode == http.StatusNotFound
+	}
+	awsErr, ok := err.(*awsError)
+	return ok && (strings.Contains(awsErr.stderr, "does not exist") || strings.Contains(awsErr.stderr, "NotFound"))
+}
+
+// UnsupportedError is returned by features that need the aws provider, such as everything asking cloudformation or
+// lambda, when the stack is deployed to another provider
+type UnsupportedError struct {
+	Feature  string
+	Provider string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s is unsupported for the %s provider", e.Feature, e.Provider)
+}
+
+func (w *Wrapper) requireAWS(feature string) error {
+	if w.provider != "aws" {
+		return &UnsupportedError{Feature: feature, Provider: w.provider}
+	}
+	return nil
+}
+
+// checkAWSOptions fails a deploy whose options need the aws provider before anything is built
+func (w *Wrapper) checkAWSOptions() error {
+	options := []struct {
+		set  bool
+		name string
+	}{
+		{w.RollbackOnFailure, "RollbackOnFailure"},
+		{w.KeepVersions > 0, "KeepVersions"},
+		{w.SmokeAfterDeploy, "SmokeAfterDeploy"},
+		{w.ResourcePreflight, "ResourcePreflight"},
+	}
+	for _, o := range options {
+		if o.set {
+			return w.requireAWS(o.name)
+		}
+	}
+	return nil
+}
+
+// awsCmd runs the aws cli against the stack's region and decodes its json output into out
+func (w *Wrapper) awsCmd(ctx context.Context, out interface{}, args ...string) error {
+	err := w.requireAWS("aws " + args[0])
+	if err != nil {
+		return err
+	}
+	args = append(args, "--region", w.Region(), "--output", "json")
+	if profile, ok := w.Opts["aws-profile"]; ok {
+		args = append(args, "--profile", profile)
+	}
+	if w.LocalStackEndpoint != "" {
+		args = append(args, "--endpoint-url", w.LocalStackEndpoint)
+	}
+
+	var stderr bytes.Buffer
+	resp, err := w.execCmdOutput(ctx, []string{}, w.yamlDirPath, ioutil.Discard, &stderr, "aws", args...)
+	if err != nil {
+		return &awsError{args: args, err: err, stderr: strings.TrimSpace(stderr.String())}
+	}
+	if out == nil || resp == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(resp), out)
+}
+
+// runAws is used by the cache and lock backends, which work without a wrapper
+func runAws(ctx context.Context, region string, args ...string) ([]byte, error) {
+	if region != "" {
+		args = append(args, "--region", region)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "aws", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return out, &awsError{args: args, err: err, stderr: strings.TrimSpace(stderr.String())}
+	}
+	return out, nil
+}
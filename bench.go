@@ -0,0 +1,117 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Invocation struct {
+	Index     int
+	Start     time.Time
+	Latency   time.Duration
+	RequestID string
+	// Report is lambda's own account of the invocation's duration and memory
+	Report   *Report
+	Response []byte
+	Err      error
+	Category ErrorCategory
+}
+
+// BenchmarkMeta describes what was benchmarked, it's written along with the results
+type BenchmarkMeta struct {
+	Function   string
+	Name       string
+	Runtime    string
+	MemorySize int
+	Region     string
+	Stage      string
+	Time       time.Time
+}
+
+type InvokeNResult struct {
+	Meta        BenchmarkMeta
+	Invocations []Invocation
+	Stats       LatencyStats
+}
+
+func (w *Wrapper) benchmarkMeta(ctx context.Context, key string) (*BenchmarkMeta, error) {
+	name := w.functionName(key)
+	config, err := w.getFunctionConfiguration(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &BenchmarkMeta{
+		Function:   key,
+		Name:       name,
+		Runtime:    config.Runtime,
+		MemorySize: config.MemorySize,
+		Region:     w.Region(),
+		Stage:      w.Stage(),
+		Time:       time.Now().UTC(),
+	}, nil
+}
+
+// invokeOnce calls the function through the lambda api, a function error is returned as a *FunctionError, the
+// latency is the invoke request's round trip, or the whole call when no response was received
+func (w *Wrapper) invokeOnce(ctx context.Context, name string, payload []byte) (*Invocation, error) {
+	start := time.Now()
+	out, err := w.lambdaInvoke(ctx, name, payload, "RequestResponse", true)
+	if err != nil {
+		return &Invocation{Start: start, Latency: time.Since(start)}, err
+	}
+	logs := out.logTail()
+	inv := &Invocation{Start: start, Latency: out.Latency, RequestID: requestIDFromLogs(logs), Report: reportFromLogs(logs), Response: out.Payload}
+	if out.FunctionError != "" {
+		if fe := parseFunctionError(out.Payload); fe != nil {
+			return inv, fe
+		}
+		return inv, errors.New(out.FunctionError + ": " + strings.TrimSpace(string(out.Payload)))
+	}
+	return inv, nil
+}
+
+// InvokeN invokes the function n times, at most concurrency at once, failed invocations are reported in their Err
+func (w *Wrapper) InvokeN(ctx context.Context, key string, payload []byte, n int, concurrency int) (*InvokeNResult, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	if n < 1 || concurrency < 1 {
+		return nil, fmt.Errorf("invalid invocation count %d or concurrency %d", n, concurrency)
+	}
+
+	meta, err := w.benchmarkMeta(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	name := meta.Name
+	samples := w.newSampleWriter(*meta)
+	defer samples.flush()
+	invocations := make([]Invocation, n)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			inv, err := w.invokeOnce(ctx, name, payload)
+			inv.Index, inv.Err, inv.Category = i, err, ClassifyError(err)
+			invocations[i] = *inv
+			samples.write(*inv)
+		}(i)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return &InvokeNResult{Meta: *meta, Invocations: invocations, Stats: latencyStats(invocations, time.Since(start))}, nil
+}
@@ -0,0 +1,179 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// InvocationPlan is what a benchmark runs against each variant, in order: cold starts, warming, then the invocations
+type InvocationPlan struct {
+	Payload     []byte
+	ColdStarts  int
+	Warm        int
+	Invocations int
+	Concurrency int
+}
+
+// Benchmark compares a function across runtimes, each runtime's sources are found as by DeployRuntimeMatrix
+type Benchmark struct {
+	Wrapper  *Wrapper
+	Function string
+	Runtimes []string
+	Plan     InvocationPlan
+	// KeepStack leaves the benchmark's stack deployed for inspection
+	KeepStack bool
+}
+
+// ServerStats summarizes the invocations' REPORT lines
+type ServerStats struct {
+	Reports       int
+	MeanDuration  time.Duration
+	P50Duration   time.Duration
+	P99Duration   time.Duration
+	MeanBilled    time.Duration
+	MaxMemoryUsed int
+	// the cold start means are of the init alone and of the init along with the handler
+	MeanColdInit  time.Duration
+	MeanColdTotal time.Duration
+}
+
+type BenchmarkRun struct {
+	Runtime    string
+	Key        string
+	Result     *InvokeNResult
+	ColdStarts *ColdStartResult
+	Server     ServerStats
+}
+
+type BenchmarkReport struct {
+	Function string
+	Runs     []BenchmarkRun
+}
+
+func serverStats(invocations []Invocation, cold *ColdStartResult) ServerStats {
+	var stats ServerStats
+	var durations []time.Duration
+	var total, billed time.Duration
+	for _, inv := range invocations {
+		r := inv.Report
+		if r == nil {
+			continue
+		}
+		durations = append(durations, r.Duration)
+		total += r.Duration
+		billed += r.BilledDuration
+		if r.MaxMemoryUsed > stats.MaxMemoryUsed {
+			stats.MaxMemoryUsed = r.MaxMemoryUsed
+		}
+	}
+	if len(durations) > 0 {
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		stats.Reports = len(durations)
+		stats.MeanDuration = total / time.Duration(len(durations))
+		stats.MeanBilled = billed / time.Duration(len(durations))
+		stats.P50Duration = percentile(durations, 0.50)
+		stats.P99Duration = percentile(durations, 0.99)
+	}
+
+	if cold != nil {
+		var init, length time.Duration
+		n := 0
+		for _, c := range cold.Cold {
+			if c.Err == nil && c.Cold {
+				init += c.Init
+				length += c.Init + c.Duration
+				n++
+			}
+		}
+		if n > 0 {
+			stats.MeanColdInit = init / time.Duration(n)
+			stats.MeanColdTotal = length / time.Duration(n)
+		}
+	}
+	return stats
+}
+
+// Run deploys every runtime variant to one stack, runs the plan against each and removes the stack
+func (b *Benchmark) Run(ctx context.Context) (report *BenchmarkReport, err error) {
+	plan := b.Plan
+	if plan.Concurrency < 1 {
+		plan.Concurrency = 1
+	}
+	if plan.Payload == nil {
+		plan.Payload = b.Wrapper.probePayload(b.Function)
+	}
+
+	matrix, err := b.Wrapper.DeployRuntimeMatrix(ctx, b.Function, b.Runtimes)
+	if matrix != nil && !b.KeepStack {
+		defer func() {
+			removeErr := matrix.Wrapper.RemoveStackContext(context.Background())
+			if removeErr == nil {
+				return
+			}
+			if err == nil {
+				err = fmt.Errorf("failed to remove the benchmark stack: %v", removeErr)
+				return
+			}
+			fmt.Fprintf(os.Stderr, "failed to remove the benchmark stack: %v\n", removeErr)
+		}()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report = &BenchmarkReport{Function: b.Function}
+	mw := matrix.Wrapper
+	for _, runtime := range b.Runtimes {
+		run := BenchmarkRun{Runtime: runtime, Key: matrix.Keys[runtime]}
+		if plan.ColdStarts > 0 {
+			run.ColdStarts, err = mw.MeasureColdStarts(ctx, run.Key, plan.Payload, plan.ColdStarts)
+			if err != nil {
+				return nil, fmt.Errorf("cold starts of %s: %v", runtime, err)
+			}
+		}
+		if plan.Warm > 0 {
+			err = mw.Warm(ctx, run.Key, plan.Warm)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if plan.Invocations > 0 {
+			run.Result, err = mw.InvokeN(ctx, run.Key, plan.Payload, plan.Invocations, plan.Concurrency)
+			if err != nil {
+				return nil, fmt.Errorf("invocations of %s: %v", runtime, err)
+			}
+			run.Server = serverStats(run.Result.Invocations, run.ColdStarts)
+		} else {
+			run.Server = serverStats(nil, run.ColdStarts)
+		}
+		report.Runs = append(report.Runs, run)
+	}
+	return report, nil
+}
+
+// String lays the runs out side by side, times in milliseconds
+func (r *BenchmarkReport) String() string {
+	ms := func(d time.Duration) string {
+		return fmt.Sprintf("%.1f", millis(d))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "benchmark of %s:\n", r.Function)
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "runtime\tinvocations\terrors\tp50\tp99\tserver mean\tserver p99\tbilled mean\tmax memory MB\tcold init")
+	for _, run := range r.Runs {
+		var stats LatencyStats
+		if run.Result != nil {
+			stats = run.Result.Stats
+		}
+		s := run.Server
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n", run.Runtime, stats.Count, stats.Errors,
+			ms(stats.P50), ms(stats.P99), ms(s.MeanDuration), ms(s.P99Duration), ms(s.MeanBilled), s.MaxMemoryUsed, ms(s.MeanColdInit))
+	}
+	tw.Flush()
+	return b.String()
+}
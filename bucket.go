@@ -0,0 +1,132 @@
+package sls
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// deploymentPrefix is where the framework uploads a service's deployments in a shared deployment bucket
+const deploymentPrefix = "serverless/"
+
+// StaleArtifacts are deployment artifacts left in a shared deployment bucket by a suffix whose stack is gone
+type StaleArtifacts struct {
+	Bucket  string
+	Prefix  string
+	Suffix  string
+	Objects int
+	Size    int64
+}
+
+type bucketObject struct {
+	Key  string
+	Size int64
+}
+
+func (w *Wrapper) listBucketPrefixes(ctx context.Context, bucket string, prefix string) ([]string, error) {
+	var resp struct {
+		CommonPrefixes []struct {
+			Prefix string
+		}
+	}
+	err := w.awsCmd(ctx, &resp, "s3api", "list-objects-v2", "--bucket", bucket, "--prefix", prefix, "--delimiter", "/")
+	if err != nil {
+		return nil, err
+	}
+
+	var prefixes []string
+	for _, p := range resp.CommonPrefixes {
+		prefixes = append(prefixes, p.Prefix)
+	}
+	return prefixes, nil
+}
+
+func (w *Wrapper) listBucketObjects(ctx context.Context, bucket string, prefix string) ([]bucketObject, error) {
+	var resp struct {
+		Contents []bucketObject
+	}
+	err := w.awsCmd(ctx, &resp, "s3api", "list-objects-v2", "--bucket", bucket, "--prefix", prefix)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Contents, nil
+}
+
+// StaleDeploymentArtifacts lists the artifacts of this service and stage in a shared deployment bucket (set with
+// provider.deploymentBucket) that belong to suffixes without a stack, removing a stack leaves those behind
+func (w *Wrapper) StaleDeploymentArtifacts(ctx context.Context, bucket string) ([]StaleArtifacts, error) {
+	pattern, err := w.servicePattern()
+	if err != nil {
+		return nil, err
+	}
+	serviceRe, err := regexp.Compile("^" + regexp.QuoteMeta(deploymentPrefix) + pattern + "/$")
+	if err != nil {
+		return nil, err
+	}
+
+	stacks, err := w.listStacks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[string]bool)
+	for _, s := range stacks {
+		live[s.StackName] = true
+	}
+
+	services, err := w.listBucketPrefixes(ctx, bucket, deploymentPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	stage := w.resolvedStage()
+	var stale []StaleArtifacts
+	for _, servicePrefix := range services {
+		m := serviceRe.FindStringSubmatch(servicePrefix)
+		if m == nil {
+			continue
+		}
+		service := strings.TrimSuffix(strings.TrimPrefix(servicePrefix, deploymentPrefix), "/")
+		if live[service+"-"+stage] {
+			continue
+		}
+
+		prefix := servicePrefix + stage + "/"
+		objects, err := w.listBucketObjects(ctx, bucket, prefix)
+		if err != nil {
+			return nil, err
+		}
+		if len(objects) == 0 {
+			continue
+		}
+		s := StaleArtifacts{Bucket: bucket, Prefix: prefix, Suffix: m[1], Objects: len(objects)}
+		for _, o := range objects {
+			s.Size += o.Size
+		}
+		stale = append(stale, s)
+	}
+	return stale, nil
+}
+
+func (w *Wrapper) DeleteDeploymentArtifacts(ctx context.Context, artifacts StaleArtifacts) error {
+	err := w.awsCmd(ctx, nil, "s3", "rm", "s3://"+artifacts.Bucket+"/"+artifacts.Prefix, "--recursive")
+	if isAwsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// CleanDeploymentBucket deletes the stale artifacts of this service and stage, with dryRun it only lists them
+func (w *Wrapper) CleanDeploymentBucket(ctx context.Context, bucket string, dryRun bool) ([]StaleArtifacts, error) {
+	stale, err := w.StaleDeploymentArtifacts(ctx, bucket)
+	if err != nil || dryRun {
+		return stale, err
+	}
+
+	for i, s := range stale {
+		err = w.DeleteDeploymentArtifacts(ctx, s)
+		if err != nil {
+			return stale[:i], err
+		}
+	}
+	return stale, nil
+}
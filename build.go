@@ -0,0 +1,481 @@
+package sls
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var outputMu sync.Mutex
+
+type buildLog struct {
+	ctx       context.Context
+	runtime   string
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	stdout    *prefixWriter
+	stderr    *prefixWriter
+	artifacts []builtArtifact
+}
+
+type builtArtifact struct {
+	path      string
+	image     string
+	functions []string
+}
+
+func (l *buildLog) addArtifact(path string, functions ...string) {
+	l.artifacts = append(l.artifacts, builtArtifact{path: path, functions: functions})
+}
+
+func (l *buildLog) addImage(image string, functions ...string) {
+	l.artifacts = append(l.artifacts, builtArtifact{image: image, functions: functions})
+}
+
+func newBuildLog(ctx context.Context, runtime string) *buildLog {
+	prefix := "[" + runtime + "] "
+	return &buildLog{
+		ctx:     ctx,
+		runtime: runtime,
+		stdout:  &prefixWriter{out: os.Stdout, prefix: prefix},
+		stderr:  &prefixWriter{out: os.Stderr, prefix: prefix},
+	}
+}
+
+func (l *buildLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+func (l *buildLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.String()
+}
+
+func (l *buildLog) execCmd(w *Wrapper, env []string, dir string, command string, cmdArgs ...string) (string, error) {
+	env = append(env, w.buildEnv(l.runtime)...)
+	if w.Reproducible {
+		env = append(env, "SOURCE_DATE_EPOCH="+w.sourceDateEpoch())
+	}
+	return w.execCmdOutput(l.ctx, env, dir, io.MultiWriter(l, l.stdout), io.MultiWriter(l, l.stderr), command, cmdArgs...)
+}
+
+func (l *buildLog) flush() {
+	l.stdout.flush()
+	l.stderr.flush()
+}
+
+type prefixWriter struct {
+	out     io.Writer
+	prefix  string
+	pending []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.pending = append(p.pending, b...)
+	for {
+		i := bytes.IndexByte(p.pending, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		err := p.writeLine(p.pending[:i+1])
+		p.pending = p.pending[i+1:]
+		if err != nil {
+			return len(b), err
+		}
+	}
+}
+
+func (p *prefixWriter) flush() {
+	if len(p.pending) == 0 {
+		return
+	}
+	p.writeLine(append(p.pending, '\n'))
+	p.pending = nil
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	_, err := io.WriteString(p.out, p.prefix)
+	if err != nil {
+		return err
+	}
+	_, err = p.out.Write(line)
+	return err
+}
+
+func (w *Wrapper) buildTimeout(runtime string) time.Duration {
+	if timeout, ok := w.BuildTimeouts[runtime]; ok {
+		return timeout
+	}
+	return w.BuildTimeout
+}
+
+func (w *Wrapper) runBuild(ctx context.Context, runtime string, build func(log *buildLog) error) ([]BuildResult, error) {
+	timeout := w.buildTimeout(runtime)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	log := newBuildLog(ctx, runtime)
+	start := time.Now()
+	err := build(log)
+	duration := time.Since(start)
+	log.flush()
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%s build timed out after %v\n%s", runtime, timeout, log.String())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s build failed: %v\n%s", runtime, err, log.String())
+	}
+	return w.buildResults(log, duration)
+}
+
+type builder func(w *Wrapper, log *buildLog, platform string, dir string) error
+
+var builders = map[string]builder{
+	"java8":  (*Wrapper).buildJava,
+	"java11": (*Wrapper).buildJava,
+	"csharp": (*Wrapper).buildCsharp,
+	"golang": (*Wrapper).buildGolang,
+	"nodejs": (*Wrapper).buildNode,
+	"python": (*Wrapper).buildPython,
+}
+
+func runtimePlatform(runtime string) string {
+	switch {
+	case strings.HasPrefix(runtime, "java8"):
+		return "java8"
+	case strings.HasPrefix(runtime, "java11"):
+		return "java11"
+	case strings.HasPrefix(runtime, "dotnet"):
+		return "csharp"
+	case strings.HasPrefix(runtime, "go"):
+		return "golang"
+	case strings.HasPrefix(runtime, "nodejs"):
+		return "nodejs"
+	case strings.HasPrefix(runtime, "python"):
+		return "python"
+	}
+	return ""
+}
+
+type platformDir struct {
+	name     string
+	platform string
+	path     string
+}
+
+func (w *Wrapper) declaredRuntimes() map[string]bool {
+	runtimes := make(map[string]bool)
+	if w.stack.Provider.Runtime != "" {
+		runtimes[w.stack.Provider.Runtime] = true
+	}
+	for _, f := range w.stack.Functions {
+		if f.Runtime != "" {
+			runtimes[f.Runtime] = true
+		}
+	}
+	return runtimes
+}
+
+// discoverPlatforms matches the yaml dir entries against the registered builders, directories named after a
+// runtime declared in the config (e.g. nodejs12.x) are built with that runtime's builder
+func (w *Wrapper) discoverPlatforms() ([]platformDir, error) {
+	entries, err := ioutil.ReadDir(w.yamlDirPath)
+	if err != nil {
+		return nil, err
+	}
+	declared := w.declaredRuntimes()
+
+	var dirs []platformDir
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		platform := name
+		if _, ok := builders[platform]; !ok {
+			if !declared[name] {
+				continue
+			}
+			platform = runtimePlatform(name)
+			if _, ok := builders[platform]; !ok {
+				continue
+			}
+		}
+		dirs = append(dirs, platformDir{name: name, platform: platform, path: filepath.Join(w.yamlDirPath, name)})
+	}
+
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].name < dirs[j].name })
+	return dirs, nil
+}
+
+func (w *Wrapper) platformsToBuild(dirs []platformDir) []platformDir {
+	var build []platformDir
+	for _, d := range dirs {
+		if !w.prebuiltPlatform(d.platform) {
+			build = append(build, d)
+		}
+	}
+	return build
+}
+
+func (w *Wrapper) Build() ([]BuildResult, error) {
+	return w.build(context.Background(), nil)
+}
+
+// build runs the builders needed by the given function keys, a nil set builds the whole stack including layers
+func (w *Wrapper) build(ctx context.Context, functions map[string]bool) ([]BuildResult, error) {
+	dirs, err := w.discoverPlatforms()
+	if err != nil {
+		return nil, err
+	}
+	dirs = w.platformsToBuild(dirs)
+	if functions != nil {
+		dirs = w.functionPlatforms(dirs, functions)
+	}
+	err = w.checkToolchains(dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := w.injectPrebuiltArtifacts(functions)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []buildJob
+	for _, d := range dirs {
+		d := d
+		build := builders[d.platform]
+		jobs = append(jobs, buildJob{
+			name:   d.name,
+			weight: platformWeights[d.platform],
+			build:  w.cachedBuild(d, func(log *buildLog) error { return build(w, log, d.name, d.path) }),
+		})
+	}
+	if functions == nil {
+		jobs = append(jobs, w.layerJobs()...)
+	}
+	jobs = append(jobs, w.imageJobs(functions)...)
+
+	built, err := w.runBuildJobs(ctx, jobs)
+	if err != nil {
+		return nil, err
+	}
+	w.injectImages(built)
+	return append(results, built...), nil
+}
+
+func (w *Wrapper) functionPlatforms(dirs []platformDir, functions map[string]bool) []platformDir {
+	platforms := make(map[string]bool)
+	for key := range functions {
+		if f, ok := w.stack.Functions[key]; ok {
+			platforms[runtimePlatform(w.functionRuntime(f))] = true
+		}
+	}
+
+	var selected []platformDir
+	for _, d := range dirs {
+		if platforms[d.platform] {
+			selected = append(selected, d)
+		}
+	}
+	return selected
+}
+
+func (w *Wrapper) buildJava(log *buildLog, platform string, javaPath string) error {
+	args := []string{"package"}
+	if w.Reproducible {
+		args = append(args, "-Dproject.build.outputTimestamp="+w.sourceDateEpoch())
+	}
+	_, err := log.execCmd(w, []string{}, javaPath, "mvn", args...)
+	if err != nil && !strings.HasPrefix(err.Error(), "WARNING") {
+		return err
+	}
+
+	jars, err := javaJars(filepath.Join(javaPath, "target"))
+	if err != nil {
+		return err
+	}
+	for _, jar := range jars {
+		if w.ArtifactsDir != "" {
+			dst := w.outputPath(platform, filepath.Base(jar))
+			err = copyFile(jar, dst)
+			if err != nil {
+				return err
+			}
+			jar = dst
+		}
+		log.addArtifact(jar)
+	}
+	return nil
+}
+
+func javaJars(targetPath string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(targetPath, "*.jar"))
+	if err != nil {
+		return nil, err
+	}
+	var jars []string
+	for _, m := range matches {
+		if strings.HasSuffix(m, "-sources.jar") || strings.HasPrefix(filepath.Base(m), "original-") {
+			continue
+		}
+		jars = append(jars, m)
+	}
+	return jars, nil
+}
+
+func (w *Wrapper) buildCsharp(log *buildLog, platform string, csharpPath string) error {
+	_, err := log.execCmd(w, []string{}, csharpPath, "dotnet", "restore")
+	if err != nil {
+		return err
+	}
+	args := []string{
+		"lambda",
+		"package",
+		"--configuration",
+		"release",
+		"--framework",
+		"netcoreapp2.1",
+		"--output-package",
+		w.outputPath(platform, "deploy.zip")}
+	if w.Reproducible {
+		args = append(args, "--msbuild-parameters", "/p:Deterministic=true /p:ContinuousIntegrationBuild=true")
+	}
+	_, err = log.execCmd(w, []string{}, csharpPath, "dotnet", args...)
+	if err != nil {
+		return err
+	}
+	log.addArtifact(w.outputPath(platform, "deploy.zip"))
+	return nil
+}
+
+func (w *Wrapper) buildGolang(log *buildLog, platform string, golangPath string) error {
+	env := []string{"GOOS=linux", "GO111MODULE=on"}
+	args := []string{"build", "-ldflags", "-s", "-ldflags", "-w"}
+	if w.Reproducible {
+		args = []string{"build", "-trimpath", "-ldflags", "-s -w -buildid="}
+	}
+	args = append(args, "-o", w.outputPath(platform, "bin", "hello"), "main.go")
+	_, err := log.execCmd(w, env, golangPath, "go", args...)
+	if err != nil {
+		return err
+	}
+	err = w.packageGolang(platform, golangPath)
+	if err != nil {
+		return err
+	}
+	log.addArtifact(w.outputPath(platform, "deploy.zip"))
+	return nil
+}
+
+func (w *Wrapper) packageGolang(platform string, golangPath string) error {
+	binName := "bin/hello"
+	if w.usesCustomRuntime(platform) {
+		binName = bootstrapName
+	}
+	files := []artifactFile{{src: w.outputPath(platform, "bin", "hello"), name: binName, mode: 0755}}
+
+	assetsPath := filepath.Join(golangPath, "assets")
+	if fileExists(assetsPath) {
+		assets, err := dirArtifactFiles(assetsPath, "assets")
+		if err != nil {
+			return err
+		}
+		files = append(files, assets...)
+	}
+
+	return zipArtifact(w.outputPath(platform, "deploy.zip"), files, w.artifactModTime())
+}
+
+// usesCustomRuntime reports whether the go platform's functions run on a provided runtime, which only starts an
+// executable named bootstrap, a function on it belongs to the platform when it's packaged from the platform's
+// artifact or has no package artifact of its own
+func (w *Wrapper) usesCustomRuntime(platform string) bool {
+	artifact := w.outputPath(platform, "deploy.zip")
+	for _, f := range w.stack.Functions {
+		runtime := w.functionRuntime(f)
+		if !strings.HasPrefix(runtime, "provided") {
+			continue
+		}
+		if f.Package.Artifact == "" || w.artifactRef(f.Package.Artifact) == artifact {
+			return true
+		}
+	}
+	return false
+}
+
+// buildPython installs the requirements into the build dir instead of next to the sources and zips both, as the
+// framework would have packaged the platform dir, into the artifact of the dir's functions
+func (w *Wrapper) buildPython(log *buildLog, platform string, pythonPath string) error {
+	if !fileExists(filepath.Join(pythonPath, "requirements.txt")) {
+		return nil
+	}
+	packagesPath := w.outputPath(platform, "dist", "site-packages")
+	err := pipInstall(log, w, pythonPath, packagesPath)
+	if err != nil {
+		return err
+	}
+
+	var files []artifactFile
+	sources, err := dirSourceFiles(pythonPath, platform, w.outputPath(platform, "dist"))
+	if err != nil {
+		return err
+	}
+	for _, f := range sources {
+		if w.packagesFile(f.name) {
+			files = append(files, f)
+		}
+	}
+	packages, err := dirArtifactFiles(packagesPath, platform)
+	if err != nil {
+		return err
+	}
+	files = append(files, packages...)
+
+	artifact := w.outputPath(platform, "deploy.zip")
+	err = zipArtifact(artifact, files, w.artifactModTime())
+	if err != nil {
+		return err
+	}
+	log.addArtifact(artifact, w.pythonFunctions(platform)...)
+	return nil
+}
+
+// pythonFunctions are the keys of the python functions whose handlers are in the platform dir
+func (w *Wrapper) pythonFunctions(platform string) []string {
+	var keys []string
+	for key, f := range w.stack.Functions {
+		if runtimePlatform(w.functionRuntime(f)) == "python" && strings.HasPrefix(f.Handler, platform+"/") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pipInstall installs the requirements of dir into a clean target
+func pipInstall(log *buildLog, w *Wrapper, dir string, target string) error {
+	err := os.RemoveAll(target)
+	if err != nil {
+		return err
+	}
+	_, err = log.execCmd(w, []string{}, dir, "pip", "install", "-r", "requirements.txt", "-t", target)
+	return err
+}
@@ -0,0 +1,105 @@
+//go:build !windows
+// +build !windows
+
+package sls
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// fakePip installs a package named after the requirement into the target dir
+const fakePip = `#!/bin/sh
+if [ "$1" = --version ]; then
+	echo "pip 21.0 from /usr/lib/python3/dist-packages/pip (python 3.8)"
+	exit 0
+fi
+target=
+prev=
+for arg in "$@"; do
+	[ "$prev" = -t ] && target=$arg
+	prev=$arg
+done
+for req in $(cat requirements.txt); do
+	mkdir -p "$target/$req"
+	echo "# $req" > "$target/$req/__init__.py"
+done
+`
+
+const pythonConfig = `service: hello-${opt:suffix}
+provider:
+  name: aws
+  runtime: python3.8
+layers:
+  deps:
+    path: layers/deps
+functions:
+  hello:
+    handler: python/handler.hello
+`
+
+func zipNames(t *testing.T, artifact string) []string {
+	r, err := zip.OpenReader(artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestBuildPythonInstallsIntoArtifactsDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sls-python")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	w := newTestWrapper(t, dir, map[string]string{
+		YamlName:                              pythonConfig,
+		"python/handler.py":                   "import requests\n",
+		"python/requirements.txt":             "requests\n",
+		"layers/deps/python/requirements.txt": "boto3\n",
+	})
+	writeExecutable(t, filepath.Join(dir, ".bin", "pip"), fakePip)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", filepath.Join(dir, ".bin")+string(os.PathListSeparator)+os.Getenv("PATH"))
+	w.ArtifactsDir = filepath.Join(dir, "build")
+
+	results, err := w.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, polluted := range []string{"python/requests", "layers/deps/python/boto3"} {
+		if fileExists(filepath.Join(dir, filepath.FromSlash(polluted))) {
+			t.Errorf("requirements were installed into the sources at %s", polluted)
+		}
+	}
+
+	artifacts := make(map[string]BuildResult)
+	for _, r := range results {
+		artifacts[r.Runtime] = r
+	}
+	function := artifacts["python"]
+	if strings.Join(function.Functions, ",") != "hello" {
+		t.Errorf("expected the python artifact for hello, got %+v", function)
+	}
+	if got := strings.Join(zipNames(t, function.Artifact), " "); got != "python/handler.py python/requests/__init__.py python/requirements.txt" {
+		t.Errorf("unexpected python artifact entries %s", got)
+	}
+	layer := artifacts["layer:deps"]
+	if got := strings.Join(zipNames(t, layer.Artifact), " "); got != "python/boto3/__init__.py python/requirements.txt" {
+		t.Errorf("unexpected layer artifact entries %s", got)
+	}
+	if refs := w.layerArtifacts(results); refs["deps"] != layer.Artifact {
+		t.Errorf("the layer isn't pointed at its artifact: %v", refs)
+	}
+}
@@ -0,0 +1,208 @@
+package sls
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ArtifactCache stores build artifacts keyed by the hash of their sources, so builds can be shared across machines
+type ArtifactCache interface {
+	Get(key string, dst string) (bool, error)
+	Put(key string, src string) error
+}
+
+type LocalCache struct {
+	Dir string
+}
+
+func (c *LocalCache) Get(key string, dst string) (bool, error) {
+	src := filepath.Join(c.Dir, filepath.FromSlash(key))
+	if !fileExists(src) {
+		return false, nil
+	}
+	return true, copyFile(src, dst)
+}
+
+func (c *LocalCache) Put(key string, src string) error {
+	return copyFile(src, filepath.Join(c.Dir, filepath.FromSlash(key)))
+}
+
+type S3Cache struct {
+	Bucket string
+	Prefix string
+	Region string
+}
+
+func (c *S3Cache) url(key string) string {
+	return "s3://" + c.Bucket + "/" + path.Join(c.Prefix, key)
+}
+
+func (c *S3Cache) aws(args ...string) ([]byte, error) {
+	return runAws(context.Background(), c.Region, args...)
+}
+
+func (c *S3Cache) Get(key string, dst string) (bool, error) {
+	// ls exits with an error when nothing matches, so a failing ls is treated as a miss
+	out, err := c.aws("s3", "ls", c.url(key))
+	if err != nil || len(strings.TrimSpace(string(out))) == 0 {
+		return false, nil
+	}
+	err = os.MkdirAll(filepath.Dir(dst), 0755)
+	if err != nil {
+		return false, err
+	}
+	_, err = c.aws("s3", "cp", "--quiet", c.url(key), dst)
+	return err == nil, err
+}
+
+func (c *S3Cache) Put(key string, src string) error {
+	_, err := c.aws("s3", "cp", "--quiet", src, c.url(key))
+	return err
+}
+
+type cacheEntry struct {
+	Path      string   `json:"path"`
+	Functions []string `json:"functions,omitempty"`
+}
+
+func (w *Wrapper) cacheable(platform string) bool {
+	switch platform {
+	case "java8", "java11", "csharp", "golang":
+		return true
+	case "nodejs":
+		return w.BundleNode
+	}
+	return false
+}
+
+func (w *Wrapper) cacheKey(d platformDir) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%t\x00%t\x00", d.name, d.platform, w.Reproducible, w.usesCustomRuntime(d.name))
+	if d.platform == "nodejs" {
+		handlers := make([]string, 0, len(w.stack.Functions))
+		for key, f := range w.stack.Functions {
+			handlers = append(handlers, key+"="+f.Handler+"@"+w.functionRuntime(f))
+		}
+		sort.Strings(handlers)
+		json.NewEncoder(h).Encode(handlers)
+	}
+
+	env := w.buildEnv(d.name)
+	sort.Strings(env)
+	json.NewEncoder(h).Encode(env)
+
+	err := hashDir(h, d.path)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (w *Wrapper) cachedBuild(d platformDir, build func(log *buildLog) error) func(log *buildLog) error {
+	if w.Cache == nil || !w.cacheable(d.platform) {
+		return build
+	}
+	return func(log *buildLog) error {
+		key, err := w.cacheKey(d)
+		if err != nil {
+			return err
+		}
+		hit, err := w.restoreFromCache(log, key)
+		if err != nil || hit {
+			return err
+		}
+		err = build(log)
+		if err != nil {
+			return err
+		}
+		return w.storeInCache(log, key)
+	}
+}
+
+func (w *Wrapper) restoreFromCache(log *buildLog, key string) (bool, error) {
+	index, err := ioutil.TempFile("", "sls-cache-")
+	if err != nil {
+		return false, err
+	}
+	index.Close()
+	defer os.Remove(index.Name())
+
+	hit, err := w.Cache.Get(key+"/index.json", index.Name())
+	if err != nil || !hit {
+		return false, err
+	}
+	data, err := ioutil.ReadFile(index.Name())
+	if err != nil {
+		return false, err
+	}
+	var entries []cacheEntry
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return false, err
+	}
+
+	for i, e := range entries {
+		dst := filepath.Join(w.yamlDirPath, filepath.FromSlash(e.Path))
+		hit, err = w.Cache.Get(fmt.Sprintf("%s/%d", key, i), dst)
+		if err != nil || !hit {
+			return false, err
+		}
+		log.addArtifact(dst, e.Functions...)
+	}
+	io.WriteString(log.stdout, "restored "+fmt.Sprint(len(entries))+" artifacts from the build cache\n")
+	return true, nil
+}
+
+func (w *Wrapper) storeInCache(log *buildLog, key string) error {
+	yamlDir, err := filepath.Abs(w.yamlDirPath)
+	if err != nil {
+		return err
+	}
+
+	var entries []cacheEntry
+	for _, a := range log.artifacts {
+		if a.path == "" {
+			continue
+		}
+		artifact, err := filepath.Abs(a.path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(yamlDir, artifact)
+		if err != nil {
+			return err
+		}
+		err = w.Cache.Put(fmt.Sprintf("%s/%d", key, len(entries)), a.path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, cacheEntry{Path: filepath.ToSlash(rel), Functions: a.functions})
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	index, err := ioutil.TempFile("", "sls-cache-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(index.Name())
+	_, err = index.Write(data)
+	index.Close()
+	if err != nil {
+		return err
+	}
+	// the index goes last so a partially stored build is never considered a hit
+	return w.Cache.Put(key+"/index.json", index.Name())
+}
@@ -0,0 +1,77 @@
+package sls
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const golangConfig = `service: hello-${opt:suffix}
+provider:
+  name: aws
+  runtime: go1.x
+functions:
+  hello:
+    handler: bin/hello
+    package:
+      artifact: golang/deploy.zip
+`
+
+func TestCacheKeyIgnoresCheckoutDir(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "sls-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	files := map[string]string{
+		YamlName:               golangConfig,
+		"golang/main.go":       "package main\n",
+		"golang/assets/a.json": "{}\n",
+	}
+	keys := make([]string, 2)
+	wrappers := make([]*Wrapper, 2)
+	dirs := make([]platformDir, 2)
+	for i, checkout := range []string{"a", "b"} {
+		dir := filepath.Join(tmp, checkout, "service")
+		wrappers[i] = newTestWrapper(t, dir, files)
+		dirs[i] = platformDir{name: "golang", platform: "golang", path: filepath.Join(dir, "golang")}
+		keys[i], err = wrappers[i].cacheKey(dirs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if keys[0] != keys[1] {
+		t.Fatal("the same sources checked out in two dirs have different cache keys")
+	}
+
+	// build outputs aren't sources
+	err = os.MkdirAll(filepath.Join(dirs[1].path, "bin"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dirs[1].path, "bin", "hello"), []byte("binary"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := wrappers[1].cacheKey(dirs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != keys[0] {
+		t.Error("a build output changed the cache key")
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dirs[1].path, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err = wrappers[1].cacheKey(dirs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key == keys[0] {
+		t.Error("changing a source kept the cache key")
+	}
+}
@@ -0,0 +1,176 @@
+package sls
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+type aliasConfiguration struct {
+	Name            string
+	FunctionVersion string
+	RoutingConfig   struct {
+		AdditionalVersionWeights map[string]float64
+	}
+}
+
+// Canary routes Weight of an alias' traffic to the Candidate version while the rest stays on Stable
+type Canary struct {
+	w         *Wrapper
+	Function  string
+	Alias     string
+	Stable    string
+	Candidate string
+	Weight    float64
+}
+
+func (w *Wrapper) getAlias(ctx context.Context, name string, alias string) (*aliasConfiguration, error) {
+	config := &aliasConfiguration{}
+	err := w.awsCmd(ctx, config, "lambda", "get-alias", "--function-name", name, "--name", alias)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (w *Wrapper) listVersionsByFunction(ctx context.Context, name string) ([]functionConfiguration, error) {
+	var resp struct {
+		Versions []functionConfiguration
+	}
+	err := w.awsCmd(ctx, &resp, "lambda", "list-versions-by-function", "--function-name", name)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Versions, nil
+}
+
+// functionVersions lists the function's published versions in ascending order
+func (w *Wrapper) functionVersions(ctx context.Context, name string) ([]int, error) {
+	configs, err := w.listVersionsByFunction(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []int
+	for _, v := range configs {
+		// skips $LATEST
+		n, err := strconv.Atoi(v.Version)
+		if err == nil {
+			versions = append(versions, n)
+		}
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+// latestVersion is the highest version the framework published for the function
+func (w *Wrapper) latestVersion(ctx context.Context, name string) (string, error) {
+	versions, err := w.functionVersions(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if len(versions) == 0 {
+		return "", fmt.Errorf("function %s has no published versions, is versionFunctions disabled?", name)
+	}
+	return strconv.Itoa(versions[len(versions)-1]), nil
+}
+
+func routingConfig(version string, weight float64) (string, error) {
+	weights := make(map[string]float64)
+	if version != "" && weight > 0 {
+		weights[version] = weight
+	}
+	config, err := json.Marshal(map[string]map[string]float64{"AdditionalVersionWeights": weights})
+	return string(config), err
+}
+
+func validWeight(weight float64) error {
+	if weight < 0 || weight >= 1 {
+		return fmt.Errorf("canary weight %v is out of [0, 1)", weight)
+	}
+	return nil
+}
+
+// StartCanary shifts weight of the alias' traffic to the function's latest version, the alias is created on the
+// latest version when it doesn't exist yet, in which case there is nothing to shift and the canary is already final
+func (w *Wrapper) StartCanary(ctx context.Context, key string, alias string, weight float64) (*Canary, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	err := validWeight(weight)
+	if err != nil {
+		return nil, err
+	}
+
+	name := w.functionName(key)
+	candidate, err := w.latestVersion(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Canary{w: w, Function: name, Alias: alias, Candidate: candidate}
+	current, err := w.getAlias(ctx, name, alias)
+	if isAwsNotFound(err) {
+		c.Stable = candidate
+		return c, w.awsCmd(ctx, nil, "lambda", "create-alias", "--function-name", name, "--name", alias, "--function-version", candidate)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if current.FunctionVersion == candidate {
+		return nil, fmt.Errorf("alias %s of %s already points at the latest version %s", alias, name, candidate)
+	}
+
+	c.Stable = current.FunctionVersion
+	return c, c.Shift(ctx, weight)
+}
+
+// Shift routes weight of the alias' traffic to the candidate
+func (c *Canary) Shift(ctx context.Context, weight float64) error {
+	err := validWeight(weight)
+	if err != nil {
+		return err
+	}
+	if c.Stable == c.Candidate && weight > 0 {
+		return fmt.Errorf("alias %s of %s has no candidate version to shift to", c.Alias, c.Function)
+	}
+	err = c.update(ctx, c.Stable, c.Candidate, weight)
+	if err != nil {
+		return err
+	}
+	c.Weight = weight
+	return nil
+}
+
+// Finalize points the whole alias at the candidate
+func (c *Canary) Finalize(ctx context.Context) error {
+	err := c.update(ctx, c.Candidate, "", 0)
+	if err != nil {
+		return err
+	}
+	c.Stable = c.Candidate
+	c.Weight = 0
+	return nil
+}
+
+// Abort points the whole alias back at the stable version
+func (c *Canary) Abort(ctx context.Context) error {
+	err := c.update(ctx, c.Stable, "", 0)
+	if err != nil {
+		return err
+	}
+	c.Candidate = c.Stable
+	c.Weight = 0
+	return nil
+}
+
+func (c *Canary) update(ctx context.Context, version string, routed string, weight float64) error {
+	routing, err := routingConfig(routed, weight)
+	if err != nil {
+		return err
+	}
+	return c.w.awsCmd(ctx, nil, "lambda", "update-alias", "--function-name", c.Function, "--name", c.Alias,
+		"--function-version", version, "--routing-config", routing)
+}
@@ -0,0 +1,80 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ciEnv turns off the framework's onboarding, login and telemetry prompts
+var ciEnv = []string{
+	"CI=true",
+	"SLS_INTERACTIVE_SETUP_ENABLE=0",
+	"SLS_INTERACTIVE_SETUP_DISABLE=1",
+	"SLS_TELEMETRY_DISABLED=1",
+	"SLS_TRACKING_DISABLED=1",
+	"SLS_NOTIFICATIONS_MODE=off",
+}
+
+// promptRe matches inquirer style questions, which are written without a trailing newline while waiting for input
+var promptRe = regexp.MustCompile(`^\? \S|\((Y/n|y/N)\)\s*$`)
+
+type PromptError struct {
+	Prompt string
+}
+
+func (e *PromptError) Error() string {
+	return fmt.Sprintf("sls prompted for input in CI mode: %s", e.Prompt)
+}
+
+// promptDetector watches a stream for prompts, calling onPrompt on the first one
+type promptDetector struct {
+	mu       sync.Mutex
+	line     string
+	onPrompt func(prompt string)
+}
+
+func (d *promptDetector) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.line += string(p)
+	lines := strings.Split(d.line, "\n")
+	d.line = lines[len(lines)-1]
+	for _, line := range lines {
+		line = strings.TrimSpace(ansiRe.ReplaceAllString(line, ""))
+		if promptRe.MatchString(line) {
+			d.onPrompt(line)
+			break
+		}
+	}
+	return len(p), nil
+}
+
+// execSlsCmdCI runs sls without any way to answer it, stopping it as soon as it prompts anyway
+func (w *Wrapper) execSlsCmdCI(ctx context.Context, funcDir string, slsCmd ...string) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var once sync.Once
+	var prompt string
+	onPrompt := func(p string) {
+		once.Do(func() {
+			prompt = p
+			cancel()
+		})
+	}
+	stdout := io.MultiWriter(os.Stdout, &promptDetector{onPrompt: onPrompt})
+	stderr := io.MultiWriter(os.Stderr, &promptDetector{onPrompt: onPrompt})
+
+	out, err := w.execCmdIO(ctx, ciEnv, funcDir, nil, stdout, stderr, "sls", slsCmd...)
+	once.Do(func() {})
+	if prompt != "" {
+		return out, &PromptError{Prompt: prompt}
+	}
+	return out, err
+}
@@ -0,0 +1,48 @@
+package sls
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type ErrorCategory string
+
+const (
+	// ErrorFunction is the handler failing, ErrorPlatform is everything failing around it, e.g. lambda or the cli
+	ErrorFunction ErrorCategory = "function"
+	ErrorThrottle ErrorCategory = "throttle"
+	ErrorTimeout  ErrorCategory = "timeout"
+	ErrorPlatform ErrorCategory = "platform"
+)
+
+var throttleMarkers = []string{"TooManyRequestsException", "Rate Exceeded", "ThrottlingException", "(429)", "429 Too Many Requests"}
+
+// ClassifyError tells what kind of failure an invocation error is, nil errors have no category
+func ClassifyError(err error) ErrorCategory {
+	if err == nil {
+		return ""
+	}
+	if err == context.DeadlineExceeded {
+		return ErrorTimeout
+	}
+	if le, ok := err.(*lambdaError); ok && le.StatusCode == http.StatusTooManyRequests {
+		return ErrorThrottle
+	}
+	if fe, ok := err.(*FunctionError); ok {
+		if fe.Type == "Sandbox.Timedout" || strings.Contains(fe.Message, "Task timed out after") {
+			return ErrorTimeout
+		}
+		return ErrorFunction
+	}
+	msg := err.Error()
+	for _, marker := range throttleMarkers {
+		if strings.Contains(msg, marker) {
+			return ErrorThrottle
+		}
+	}
+	if strings.Contains(msg, "Task timed out after") {
+		return ErrorTimeout
+	}
+	return ErrorPlatform
+}
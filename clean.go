@@ -0,0 +1,74 @@
+package sls
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var platformOutputs = map[string][]string{
+	"java8":  {"target"},
+	"java11": {"target"},
+	"csharp": {"bin", "obj", "deploy.zip"},
+	"golang": {"bin", "deploy.zip"},
+	"nodejs": {"dist"},
+	"python": {"dist", "deploy.zip"},
+}
+
+// containsDir tells whether dir is root or inside it, symlinks resolved
+func containsDir(root string, dir string) bool {
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// CleanArtifacts removes the builders' outputs, dependencies such as node_modules are kept, an ArtifactsDir holding
+// the service dir is refused rather than removed
+func (w *Wrapper) CleanArtifacts() error {
+	dirs, err := w.discoverPlatforms()
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	for _, d := range dirs {
+		for _, output := range platformOutputs[d.platform] {
+			paths = append(paths, filepath.Join(d.path, output))
+		}
+	}
+	for _, layer := range w.stack.Layers {
+		if layer.Path != "" {
+			paths = append(paths, w.outputPath(layer.Path, "dist"))
+		}
+		if layer.Package.Artifact != "" {
+			paths = append(paths, w.artifactRef(layer.Package.Artifact))
+		}
+	}
+	if w.ArtifactsDir != "" {
+		yamlDir, err := filepath.Abs(w.yamlDirPath)
+		if err != nil {
+			return err
+		}
+		if containsDir(w.artifactsRoot(), yamlDir) {
+			return fmt.Errorf("refusing to remove the artifacts dir %s, it holds the service dir %s", w.artifactsRoot(), yamlDir)
+		}
+		paths = append(paths, w.artifactsRoot())
+	}
+
+	for _, p := range paths {
+		err = os.RemoveAll(p)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
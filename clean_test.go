@@ -0,0 +1,44 @@
+package sls
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanArtifactsRefusesServiceDir(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "sls-clean")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "service")
+	w := newTestWrapper(t, dir, map[string]string{
+		YamlName:         golangConfig,
+		"golang/main.go": "package main\n",
+		"build/out.zip":  "zip",
+	})
+
+	for _, root := range []string{".", "..", tmp, dir} {
+		w.ArtifactsDir = root
+		if err := w.CleanArtifacts(); err == nil {
+			t.Errorf("cleaning with the artifacts dir %s succeeded", root)
+		}
+		if !fileExists(filepath.Join(dir, "golang", "main.go")) {
+			t.Fatalf("cleaning with the artifacts dir %s removed the sources", root)
+		}
+	}
+
+	w.ArtifactsDir = "build"
+	err = w.CleanArtifacts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileExists(filepath.Join(dir, "build")) {
+		t.Error("the artifacts dir wasn't removed")
+	}
+	if !fileExists(filepath.Join(dir, "golang", "main.go")) {
+		t.Error("cleaning removed the sources")
+	}
+}
@@ -0,0 +1,44 @@
+package sls
+
+import (
+	"sync"
+	"time"
+)
+
+// WithSuffix returns a wrapper for another suffix of the same service, sharing this wrapper's settings
+func (w *Wrapper) WithSuffix(suffix string) (*Wrapper, error) {
+	other, err := newWrapper(w.provider, w.yamlDirPath, w.configName, suffix)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := *w
+	clone.stack = other.stack
+	clone.suffix = suffix
+	clone.infoCache = newInfoCache()
+	clone.processMu = &sync.Mutex{}
+	clone.offline = nil
+	clone.dev = nil
+
+	clone.Opts = make(map[string]string, len(w.Opts))
+	for k, v := range w.Opts {
+		clone.Opts[k] = v
+	}
+	clone.BuildEnvs = make(map[string]BuildEnv, len(w.BuildEnvs))
+	for k, v := range w.BuildEnvs {
+		clone.BuildEnvs[k] = v
+	}
+	clone.BuildTimeouts = make(map[string]time.Duration, len(w.BuildTimeouts))
+	for k, v := range w.BuildTimeouts {
+		clone.BuildTimeouts[k] = v
+	}
+	clone.PrebuiltArtifacts = make(map[string]string, len(w.PrebuiltArtifacts))
+	for k, v := range w.PrebuiltArtifacts {
+		clone.PrebuiltArtifacts[k] = v
+	}
+	clone.ProbePayloads = make(map[string][]byte, len(w.ProbePayloads))
+	for k, v := range w.ProbePayloads {
+		clone.ProbePayloads[k] = v
+	}
+	return &clone, nil
+}
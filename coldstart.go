@@ -0,0 +1,121 @@
+package sls
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// coldStartEnv is changed before each cold invocation, any configuration change makes lambda start new instances
+const coldStartEnv = "SLS_COLD_START"
+
+// restoreEnvTimeout bounds restoring the environment, which runs even when the measurement's context is done
+const restoreEnvTimeout = 2 * time.Minute
+
+type ColdStart struct {
+	// Cold is set when lambda reported an init duration for the invocation
+	Cold     bool
+	Init     time.Duration
+	Duration time.Duration
+	// Latency is measured by the client and includes the invoke round trip
+	Latency time.Duration
+	Err     error
+}
+
+type ColdStartResult struct {
+	Key        string
+	Runtime    string
+	MemorySize int
+	Cold       []ColdStart
+	Warm       []ColdStart
+}
+
+func (w *Wrapper) updateEnvironment(ctx context.Context, name string, vars map[string]string) error {
+	env, err := json.Marshal(map[string]map[string]string{"Variables": vars})
+	if err != nil {
+		return err
+	}
+	err = w.awsCmd(ctx, nil, "lambda", "update-function-configuration", "--function-name", name, "--environment", string(env))
+	if err != nil {
+		return err
+	}
+	for {
+		ready, err := w.functionReady(ctx, name)
+		if err != nil || ready {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		sleepContext(ctx, readyPollInterval)
+	}
+}
+
+func (w *Wrapper) timedInvoke(ctx context.Context, name string, payload []byte) ColdStart {
+	start := time.Now()
+	out, err := w.lambdaInvoke(ctx, name, payload, "RequestResponse", true)
+	if err != nil {
+		return ColdStart{Latency: time.Since(start), Err: err}
+	}
+	sample := ColdStart{Latency: out.Latency}
+	if out.FunctionError != "" {
+		sample.Err = parseFunctionError(out.Payload)
+		if sample.Err == nil {
+			sample.Err = fmt.Errorf("%s: %s", out.FunctionError, out.Payload)
+		}
+	}
+	if report := reportFromLogs(out.logTail()); report != nil {
+		sample.Init, sample.Duration = report.InitDuration, report.Duration
+	}
+	sample.Cold = sample.Init > 0
+	return sample
+}
+
+// MeasureColdStarts forces n cold starts of the function by changing its environment, each cold invocation is followed
+// by a warm one to compare against, the function's environment is restored afterwards
+func (w *Wrapper) MeasureColdStarts(ctx context.Context, key string, payload []byte, n int) (*ColdStartResult, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	name := w.functionName(key)
+	config, err := w.getFunctionConfiguration(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	original := config.Environment.Variables
+	if original == nil {
+		original = map[string]string{}
+	}
+
+	vars := make(map[string]string, len(original)+1)
+	for k, v := range original {
+		vars[k] = v
+	}
+	result := &ColdStartResult{Key: key, Runtime: config.Runtime, MemorySize: config.MemorySize}
+	for i := 0; i < n; i++ {
+		err = ctx.Err()
+		if err != nil {
+			break
+		}
+		vars[coldStartEnv] = strconv.FormatInt(time.Now().UnixNano(), 10)
+		err = w.updateEnvironment(ctx, name, vars)
+		if err != nil {
+			break
+		}
+		result.Cold = append(result.Cold, w.timedInvoke(ctx, name, payload))
+		result.Warm = append(result.Warm, w.timedInvoke(ctx, name, payload))
+	}
+
+	restoreCtx, cancel := context.WithTimeout(context.Background(), restoreEnvTimeout)
+	defer cancel()
+	restoreErr := w.updateEnvironment(restoreCtx, name, original)
+	if err != nil {
+		return nil, err
+	}
+	if restoreErr != nil {
+		return nil, fmt.Errorf("failed to restore the environment of %s: %v", name, restoreErr)
+	}
+	return result, nil
+}
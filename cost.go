@@ -0,0 +1,165 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	hoursPerMonth     = 730
+	defaultMemorySize = 1024
+)
+
+// Prices are in USD, the defaults are lambda's and dynamodb's on demand x86 prices in us-east-1
+type Prices struct {
+	PerMillionRequests      float64
+	PerGBSecond             float64
+	ProvisionedPerGBSecond  float64
+	DynamoDBPerWCUHour      float64
+	DynamoDBPerRCUHour      float64
+	DynamoDBPerMillionWrite float64
+	DynamoDBPerMillionRead  float64
+}
+
+var DefaultPrices = Prices{
+	PerMillionRequests:      0.20,
+	PerGBSecond:             0.0000166667,
+	ProvisionedPerGBSecond:  0.0000041667,
+	DynamoDBPerWCUHour:      0.00065,
+	DynamoDBPerRCUHour:      0.00013,
+	DynamoDBPerMillionWrite: 1.25,
+	DynamoDBPerMillionRead:  0.25,
+}
+
+type CostOptions struct {
+	// Invocations are the expected monthly invocations by function key, DefaultInvocations applies to the rest
+	Invocations        map[string]int64
+	DefaultInvocations int64
+	// Durations are the expected average durations by function key, DefaultDuration applies to the rest
+	Durations       map[string]time.Duration
+	DefaultDuration time.Duration
+	// TableReads and TableWrites are the expected monthly requests to on demand tables by logical id
+	TableReads  map[string]int64
+	TableWrites map[string]int64
+	// Prices defaults to DefaultPrices
+	Prices *Prices
+}
+
+type CostItem struct {
+	Name    string  `json:"name"`
+	Kind    string  `json:"kind"`
+	Monthly float64 `json:"monthly"`
+	Detail  string  `json:"detail"`
+}
+
+type CostReport struct {
+	Items []CostItem `json:"items"`
+	Total float64    `json:"total"`
+	// Unpriced are resources whose cost depends on usage the estimate can't know about, such as bucket storage
+	Unpriced []string `json:"unpriced"`
+}
+
+func configMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func configNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// EstimateCost estimates the stack's monthly cost from its resolved config and the expected usage
+func (w *Wrapper) EstimateCost(ctx context.Context, opts CostOptions) (*CostReport, error) {
+	config, err := w.PrintConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prices := DefaultPrices
+	if opts.Prices != nil {
+		prices = *opts.Prices
+	}
+
+	report := &CostReport{}
+	add := func(item CostItem) {
+		report.Items = append(report.Items, item)
+		report.Total += item.Monthly
+	}
+
+	memory := float64(defaultMemorySize)
+	if m, ok := configNumber(configMap(config.Raw["provider"])["memorySize"]); ok {
+		memory = m
+	}
+
+	functions := configMap(config.Raw["functions"])
+	for _, key := range sortedKeys(functions) {
+		f := configMap(functions[key])
+		gb := memory / 1024
+		if m, ok := configNumber(f["memorySize"]); ok {
+			gb = m / 1024
+		}
+
+		invocations, ok := opts.Invocations[key]
+		if !ok {
+			invocations = opts.DefaultInvocations
+		}
+		duration, ok := opts.Durations[key]
+		if !ok {
+			duration = opts.DefaultDuration
+		}
+		// lambda bills the duration in 1ms increments
+		seconds := float64(duration.Round(time.Millisecond)) / float64(time.Second)
+		requests := float64(invocations) / 1e6 * prices.PerMillionRequests
+		compute := float64(invocations) * seconds * gb * prices.PerGBSecond
+		add(CostItem{Name: key, Kind: "function", Monthly: requests + compute,
+			Detail: fmt.Sprintf("%d invocations of %v at %v GB", invocations, duration, gb)})
+
+		if concurrency, ok := configNumber(f["provisionedConcurrency"]); ok && concurrency > 0 {
+			add(CostItem{Name: key, Kind: "provisioned concurrency", Monthly: concurrency * gb * hoursPerMonth * 3600 * prices.ProvisionedPerGBSecond,
+				Detail: fmt.Sprintf("%v instances at %v GB", concurrency, gb)})
+		}
+	}
+
+	resources := configMap(configMap(config.Raw["resources"])["Resources"])
+	for _, id := range sortedKeys(resources) {
+		r := configMap(resources[id])
+		props := configMap(r["Properties"])
+		switch r["Type"] {
+		case "AWS::DynamoDB::Table":
+			if props["BillingMode"] == "PAY_PER_REQUEST" {
+				reads, writes := opts.TableReads[id], opts.TableWrites[id]
+				add(CostItem{Name: id, Kind: "dynamodb table",
+					Monthly: float64(reads)/1e6*prices.DynamoDBPerMillionRead + float64(writes)/1e6*prices.DynamoDBPerMillionWrite,
+					Detail:  fmt.Sprintf("on demand, %d reads and %d writes", reads, writes)})
+				continue
+			}
+			throughput := configMap(props["ProvisionedThroughput"])
+			rcu, _ := configNumber(throughput["ReadCapacityUnits"])
+			wcu, _ := configNumber(throughput["WriteCapacityUnits"])
+			add(CostItem{Name: id, Kind: "dynamodb table",
+				Monthly: (rcu*prices.DynamoDBPerRCUHour + wcu*prices.DynamoDBPerWCUHour) * hoursPerMonth,
+				Detail:  fmt.Sprintf("%v RCU and %v WCU provisioned", rcu, wcu)})
+		default:
+			report.Unpriced = append(report.Unpriced, id)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,52 @@
+package sls
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+type phaseTimer struct {
+	phases  []Phase
+	current string
+	started time.Time
+}
+
+func (t *phaseTimer) start(name string) {
+	t.stop()
+	t.current = name
+	t.started = time.Now()
+}
+
+func (t *phaseTimer) stop() []Phase {
+	if t.current != "" {
+		t.phases = append(t.phases, Phase{Name: t.current, Duration: time.Since(t.started)})
+		t.current = ""
+	}
+	return t.phases
+}
+
+// DeadlineError is a deploy that ran out of its DeployTimeout, Phases shows where the time went
+type DeadlineError struct {
+	StackName string
+	Timeout   time.Duration
+	Phases    []Phase
+	Err       error
+}
+
+func (e *DeadlineError) Error() string {
+	var phases []string
+	for _, p := range e.Phases {
+		phases = append(phases, fmt.Sprintf("%s %v", p.Name, p.Duration.Round(time.Second)))
+	}
+	last := "lock"
+	if len(e.Phases) > 0 {
+		last = e.Phases[len(e.Phases)-1].Name
+	}
+	return fmt.Sprintf("deploy of stack %s exceeded its %v deadline during %s (%s): %v", e.StackName, e.Timeout, last, strings.Join(phases, ", "), e.Err)
+}
@@ -0,0 +1,110 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+type FunctionResult struct {
+	Name         string
+	Arn          string
+	QualifiedArn string
+}
+
+type DeployResult struct {
+	StackName string
+	Region    string
+	Stage     string
+	Suffix    string
+	Functions map[string]FunctionResult
+	Endpoints []Endpoint
+	Builds    []BuildResult
+	// PromotedFrom is the stage whose artifacts Promote deployed
+	PromotedFrom string
+}
+
+func (w *Wrapper) deployArgs() []string {
+	args := append([]string{"deploy", "--verbose"}, w.Provider().DeployFlags()...)
+	if w.Force {
+		args = append(args, "--force")
+	}
+	return args
+}
+
+func (w *Wrapper) resolvedStage() string {
+	if stage, ok := w.Opts["stage"]; ok {
+		return stage
+	}
+	if w.stack.Provider.Stage != "" && !strings.Contains(w.stack.Provider.Stage, "${") {
+		return w.stack.Provider.Stage
+	}
+	return defaultStage
+}
+
+// StackName is the name of the deployed CloudFormation stack, the framework names it <service>-<stage>
+func (w *Wrapper) StackName() string {
+	return strings.Replace(w.stack.StackId, "${opt:suffix}", w.suffix, -1) + "-" + w.resolvedStage()
+}
+
+// deployResult doesn't fail the deploy that already succeeded, when the stack's info can't be read the result is
+// made of what the deploy output and the config tell
+func (w *Wrapper) deployResult(ctx context.Context, deployOut string, builds []BuildResult) *DeployResult {
+	w.cacheInfo(nil)
+	info, err := w.parseInfo(ctx, deployOut)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read the info of stack %s: %v\n", w.StackName(), err)
+		return w.newDeployResult(w.Provider().ParseInfo(deployOut), builds)
+	}
+	if len(info.Functions) == 0 && len(w.stack.Functions) > 0 {
+		full, err := w.Info(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read the info of stack %s: %v\n", w.StackName(), err)
+			return w.newDeployResult(info, builds)
+		}
+		info = full
+	} else {
+		w.cacheInfo(info)
+	}
+	return w.newDeployResult(info, builds)
+}
+
+func (w *Wrapper) newDeployResult(info *ServiceInfo, builds []BuildResult) *DeployResult {
+	result := &DeployResult{
+		StackName: info.Stack,
+		Region:    info.Region,
+		Stage:     info.Stage,
+		Suffix:    w.suffix,
+		Functions: make(map[string]FunctionResult),
+		Endpoints: info.Endpoints,
+		Builds:    builds,
+
+		PromotedFrom: w.promotedFrom,
+	}
+	if result.StackName == "" {
+		result.StackName = w.StackName()
+	}
+	if result.Region == "" {
+		result.Region = w.Region()
+	}
+	if result.Stage == "" {
+		result.Stage = w.resolvedStage()
+	}
+
+	keys := make([]string, 0, len(w.stack.Functions))
+	for key := range w.stack.Functions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name, ok := info.Functions[key]
+		if !ok {
+			name = w.stack.Functions[key].Name
+		}
+		qualified := info.functionQualifiedArn(key)
+		result.Functions[key] = FunctionResult{Name: name, Arn: unqualifiedArn(qualified), QualifiedArn: qualified}
+	}
+	return result
+}
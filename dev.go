@@ -0,0 +1,115 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+)
+
+const devStartTimeout = 5 * time.Minute
+
+type DevEventKind string
+
+const (
+	DevRequest  DevEventKind = "request"
+	DevResponse DevEventKind = "response"
+	DevLog      DevEventKind = "log"
+)
+
+// DevEvent is a line of sls dev's event stream, logs are attributed to the function of the last request
+type DevEvent struct {
+	Time     time.Time
+	Kind     DevEventKind
+	Function string
+	Message  string
+}
+
+var (
+	// devReadyRe matches the line sls dev prints once the functions are instrumented and it's listening
+	devReadyRe = regexp.MustCompile(`(?i)dev mode (initialized|ready)|waiting for (events|invocations)`)
+	// devEventRe matches invocations, → for requests and ← for responses, optionally after the service type
+	devEventRe = regexp.MustCompile(`^(→|←)\s+(?:AWS Lambda\s+ϟ\s+)?(\S+)\s*(.*)$`)
+)
+
+// DevSession is a running sls dev, the deployed functions forward their invocations to the local handlers while it
+// runs
+type DevSession struct {
+	w       *Wrapper
+	process *background
+}
+
+// Wait blocks until sls dev exits, returning its error
+func (d *DevSession) Wait() error {
+	return d.process.wait()
+}
+
+// Invoke invokes the deployed function, which sls dev routes to the local handler
+func (d *DevSession) Invoke(ctx context.Context, key string, payload []byte) (*InvokeResult, error) {
+	select {
+	case <-d.process.done:
+		return nil, errors.New("sls dev isn't running")
+	default:
+	}
+	return d.w.Invoke(ctx, key, payload)
+}
+
+// devParser turns sls dev's output into events once it's ready
+type devParser struct {
+	ready    bool
+	function string
+	fn       func(DevEvent)
+}
+
+func (p *devParser) line(line string) bool {
+	if !p.ready {
+		p.ready = devReadyRe.MatchString(line)
+		return p.ready
+	}
+	if line == "" || p.fn == nil {
+		return false
+	}
+
+	e := DevEvent{Time: time.Now(), Kind: DevLog, Function: p.function, Message: line}
+	if m := devEventRe.FindStringSubmatch(line); m != nil {
+		e.Kind = DevRequest
+		if m[1] == "←" {
+			e.Kind = DevResponse
+		}
+		e.Function = m[2]
+		e.Message = m[3]
+		p.function = m[2]
+	}
+	p.fn(e)
+	return false
+}
+
+// StartDev runs sls dev in the background, which needs v4 of the framework, and returns once invocations are routed
+// to the local handlers, fn receives its event stream, it runs until StopDev or until ctx is done
+func (w *Wrapper) StartDev(ctx context.Context, fn func(DevEvent)) (*DevSession, error) {
+	w.processMu.Lock()
+	defer w.processMu.Unlock()
+	if w.dev != nil {
+		return nil, errors.New("sls dev is already running")
+	}
+
+	p := &devParser{fn: fn}
+	process, err := w.startBackground(ctx, devStartTimeout, p.line, "dev")
+	if err != nil {
+		return nil, err
+	}
+	w.dev = &DevSession{w: w, process: process}
+	return w.dev, nil
+}
+
+// StopDev interrupts the running sls dev, which restores the deployed functions before exiting
+func (w *Wrapper) StopDev() error {
+	w.processMu.Lock()
+	d := w.dev
+	w.dev = nil
+	w.processMu.Unlock()
+	if d == nil {
+		return nil
+	}
+	return d.process.stop()
+}
@@ -0,0 +1,248 @@
+package sls
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	ChangeAdd    = "add"
+	ChangeModify = "modify"
+	ChangeRemove = "remove"
+
+	packagedTemplateName = "cloudformation-template-update-stack.json"
+)
+
+type Change struct {
+	Name   string `json:"name"`
+	Type   string `json:"type,omitempty"`
+	Action string `json:"action"`
+	// CodeChanged marks functions whose packaged code differs from the deployed code
+	CodeChanged bool `json:"codeChanged,omitempty"`
+}
+
+// StackDiff is what a deploy would change, Functions are named by their keys and Resources by their logical ids
+type StackDiff struct {
+	StackName string   `json:"stackName"`
+	Functions []Change `json:"functions"`
+	Resources []Change `json:"resources"`
+}
+
+func (d *StackDiff) Empty() bool {
+	return len(d.Functions) == 0 && len(d.Resources) == 0
+}
+
+func (d *StackDiff) String() string {
+	if d.Empty() {
+		return fmt.Sprintf("stack %s is up to date\n", d.StackName)
+	}
+
+	symbols := map[string]string{ChangeAdd: "+", ChangeModify: "~", ChangeRemove: "-"}
+	var b strings.Builder
+	fmt.Fprintf(&b, "stack %s:\n", d.StackName)
+	for _, c := range d.Functions {
+		code := ""
+		if c.CodeChanged {
+			code = " (code)"
+		}
+		fmt.Fprintf(&b, "%s function %s%s\n", symbols[c.Action], c.Name, code)
+	}
+	for _, c := range d.Resources {
+		fmt.Fprintf(&b, "%s %s %s\n", symbols[c.Action], c.Type, c.Name)
+	}
+	return b.String()
+}
+
+type templateResource struct {
+	Type       string
+	Properties map[string]interface{}
+}
+
+type template struct {
+	Resources map[string]templateResource
+}
+
+func readTemplate(p string) (*template, error) {
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	t := &template{}
+	return t, json.Unmarshal(data, t)
+}
+
+// deployedTemplate is nil when the stack doesn't exist
+func (w *Wrapper) deployedTemplate(ctx context.Context) (*template, error) {
+	var resp struct {
+		TemplateBody json.RawMessage
+	}
+	err := w.awsCmd(ctx, &resp, "cloudformation", "get-template", "--stack-name", w.StackName())
+	if isAwsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	body := []byte(resp.TemplateBody)
+	// the framework deploys json templates, which the cli returns either decoded or as a string
+	var s string
+	if json.Unmarshal(body, &s) == nil {
+		body = []byte(s)
+	}
+	t := &template{}
+	err = json.Unmarshal(body, t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the template of stack %s: %v", w.StackName(), err)
+	}
+	return t, nil
+}
+
+// withoutCode drops the function code location, which the framework changes on every package
+func withoutCode(r templateResource) map[string]interface{} {
+	if r.Type != "AWS::Lambda::Function" {
+		return r.Properties
+	}
+	props := make(map[string]interface{}, len(r.Properties))
+	for k, v := range r.Properties {
+		if k != "Code" {
+			props[k] = v
+		}
+	}
+	return props
+}
+
+// packagedCodeSha256 is the lambda style checksum of the function's zip in the package dir
+func packagedCodeSha256(packageDir string, r templateResource) (string, error) {
+	code, _ := r.Properties["Code"].(map[string]interface{})
+	key, _ := code["S3Key"].(string)
+	if key == "" {
+		return "", nil
+	}
+	// artifacts given in the config are uploaded from where they are instead of the package dir
+	_, sum, err := fileChecksum(filepath.Join(packageDir, path.Base(key)))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	raw, err := hex.DecodeString(sum)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// Diff packages the stack and compares the result against the deployed stack without changing anything
+func (w *Wrapper) Diff(ctx context.Context) (*StackDiff, error) {
+	err := w.requireAWS("diffing a stack")
+	if err != nil {
+		return nil, err
+	}
+	packageDir, err := ioutil.TempDir("", "sls-diff")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(packageDir)
+
+	_, err = w.Package(ctx, packageDir)
+	if err != nil {
+		return nil, err
+	}
+	packaged, err := readTemplate(filepath.Join(packageDir, packagedTemplateName))
+	if err != nil {
+		return nil, err
+	}
+	deployed, err := w.deployedTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if deployed == nil {
+		deployed = &template{}
+	}
+
+	functionKeys := make(map[string]string)
+	for key := range w.stack.Functions {
+		functionKeys[functionLogicalId(key)+"LambdaFunction"] = key
+	}
+	functionKey := func(id string) string {
+		if key, ok := functionKeys[id]; ok {
+			return key
+		}
+		return strings.TrimSuffix(id, "LambdaFunction")
+	}
+
+	diff := &StackDiff{StackName: w.StackName()}
+	for id, r := range packaged.Resources {
+		old, ok := deployed.Resources[id]
+		action := ""
+		codeChanged := false
+		switch {
+		case !ok:
+			action = ChangeAdd
+		case old.Type != r.Type || !reflect.DeepEqual(withoutCode(old), withoutCode(r)):
+			action = ChangeModify
+		}
+
+		if r.Type == "AWS::Lambda::Function" && action != ChangeAdd {
+			codeChanged, err = w.functionCodeChanged(ctx, packageDir, functionKey(id), r)
+			if err != nil {
+				return nil, err
+			}
+			if codeChanged {
+				action = ChangeModify
+			}
+		}
+		if action == "" {
+			continue
+		}
+
+		diff.Resources = append(diff.Resources, Change{Name: id, Type: r.Type, Action: action})
+		if r.Type == "AWS::Lambda::Function" {
+			diff.Functions = append(diff.Functions, Change{Name: functionKey(id), Type: r.Type, Action: action, CodeChanged: codeChanged || action == ChangeAdd})
+		}
+	}
+	for id, r := range deployed.Resources {
+		if _, ok := packaged.Resources[id]; ok {
+			continue
+		}
+		diff.Resources = append(diff.Resources, Change{Name: id, Type: r.Type, Action: ChangeRemove})
+		if r.Type == "AWS::Lambda::Function" {
+			diff.Functions = append(diff.Functions, Change{Name: functionKey(id), Type: r.Type, Action: ChangeRemove})
+		}
+	}
+
+	sort.Slice(diff.Functions, func(i, j int) bool { return diff.Functions[i].Name < diff.Functions[j].Name })
+	sort.Slice(diff.Resources, func(i, j int) bool { return diff.Resources[i].Name < diff.Resources[j].Name })
+	return diff, nil
+}
+
+func (w *Wrapper) functionCodeChanged(ctx context.Context, packageDir string, key string, r templateResource) (bool, error) {
+	sum, err := packagedCodeSha256(packageDir, r)
+	if err != nil || sum == "" {
+		return false, err
+	}
+	name, _ := r.Properties["FunctionName"].(string)
+	if name == "" {
+		name = w.functionName(key)
+	}
+	config, err := w.getFunctionConfiguration(ctx, name)
+	if isAwsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return config.CodeSha256 != sum, nil
+}
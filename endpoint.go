@@ -0,0 +1,142 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const apiKeyHeader = "x-api-key"
+
+type httpEvent struct {
+	kind   string
+	method string
+	path   string
+}
+
+// httpEvents lists the function's api gateway and http api events, which are either "METHOD path" strings or maps
+func httpEvents(f FunctionMeta) []httpEvent {
+	var events []httpEvent
+	for _, e := range f.Events {
+		for _, kind := range []string{"http", "httpApi"} {
+			v, ok := e[kind]
+			if !ok {
+				continue
+			}
+			event := httpEvent{kind: kind, method: "ANY"}
+			switch v := v.(type) {
+			case string:
+				if v == "*" {
+					event.path = "*"
+				} else if fields := strings.Fields(v); len(fields) == 2 {
+					event.method, event.path = fields[0], fields[1]
+				}
+			case map[interface{}]interface{}:
+				if m, ok := v["method"].(string); ok {
+					event.method = m
+				}
+				event.path, _ = v["path"].(string)
+			}
+			event.method = strings.ToUpper(event.method)
+			event.path = strings.Trim(event.path, "/")
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (e httpEvent) matches(endpoint Endpoint) bool {
+	if endpoint.Function != "" {
+		return false
+	}
+	method := strings.ToUpper(endpoint.Method)
+	if e.method != method && e.method != "ANY" && e.method != "*" {
+		return false
+	}
+	if e.path == "*" {
+		return method == "ANY" || method == "*"
+	}
+	u, err := url.Parse(endpoint.URL)
+	if err != nil {
+		return false
+	}
+	p := strings.Trim(u.Path, "/")
+	return p == e.path || strings.HasSuffix(p, "/"+e.path)
+}
+
+// Endpoint finds the url a function is exposed at, either its function url or the first of its http events
+func (w *Wrapper) Endpoint(ctx context.Context, key string) (*Endpoint, error) {
+	f, ok := w.stack.Functions[key]
+	if !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	info, err := w.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range info.Endpoints {
+		if e.Function == key {
+			e := e
+			return &e, nil
+		}
+	}
+	for _, event := range httpEvents(f) {
+		for _, e := range info.Endpoints {
+			if event.matches(e) {
+				e := e
+				return &e, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("function %s has no endpoint", key)
+}
+
+// endpointTransport resolves relative request urls against the endpoint and authenticates with the api key
+type endpointTransport struct {
+	base   *url.URL
+	apiKey string
+	next   http.RoundTripper
+}
+
+func (t *endpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// round trippers must not modify the caller's request
+	r := *req
+	r.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	if !r.URL.IsAbs() {
+		r.URL = t.base.ResolveReference(r.URL)
+		r.Host = ""
+	}
+	if t.apiKey != "" && r.Header.Get(apiKeyHeader) == "" {
+		r.Header.Set(apiKeyHeader, t.apiKey)
+	}
+	return t.next.RoundTrip(&r)
+}
+
+// HTTPClient returns a client for the function's endpoint, relative urls such as "" or "?id=1" are resolved
+// against it and apiKey, when set, is sent as the x-api-key header
+func (w *Wrapper) HTTPClient(ctx context.Context, key string, apiKey string) (*http.Client, error) {
+	endpoint, err := w.Endpoint(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	base, err := url.Parse(endpoint.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: &endpointTransport{base: base, apiKey: apiKey, next: http.DefaultTransport}}, nil
+}
+
+// APIKeys returns the api keys the stack created by name, for authenticating against private endpoints
+func (w *Wrapper) APIKeys(ctx context.Context) (map[string]string, error) {
+	info, err := w.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return info.APIKeys, nil
+}
@@ -0,0 +1,59 @@
+package sls
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type BuildEnv struct {
+	Vars        map[string]string
+	PathPrepend []string
+}
+
+func (w *Wrapper) buildEnv(runtime string) []string {
+	buildEnv, ok := w.BuildEnvs[runtime]
+	if !ok {
+		return nil
+	}
+
+	var env []string
+	for k, v := range buildEnv.Vars {
+		env = append(env, k+"="+v)
+	}
+	if len(buildEnv.PathPrepend) > 0 {
+		paths := append(append([]string{}, buildEnv.PathPrepend...), os.Getenv("PATH"))
+		env = append(env, "PATH="+strings.Join(paths, string(os.PathListSeparator)))
+	}
+	return env
+}
+
+func envValue(env []string, key string) (string, bool) {
+	for i := len(env) - 1; i >= 0; i-- {
+		if strings.HasPrefix(env[i], key+"=") {
+			return strings.TrimPrefix(env[i], key+"="), true
+		}
+	}
+	return "", false
+}
+
+// exec.Command resolves the binary with our own PATH, so an overridden PATH has to be searched manually
+func lookPathEnv(command string, env []string) (string, error) {
+	pathEnv, ok := envValue(env, "PATH")
+	if !ok || strings.Contains(command, string(os.PathSeparator)) {
+		return exec.LookPath(command)
+	}
+
+	for _, dir := range filepath.SplitList(pathEnv) {
+		if dir == "" {
+			dir = "."
+		}
+		p, err := exec.LookPath(filepath.Join(dir, command))
+		if err == nil {
+			return p, nil
+		}
+	}
+	return "", errors.New("executable file not found in PATH: " + command)
+}
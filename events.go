@@ -0,0 +1,152 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type StackEvent struct {
+	EventId              string
+	Timestamp            time.Time
+	LogicalResourceId    string
+	PhysicalResourceId   string
+	ResourceType         string
+	ResourceStatus       string
+	ResourceStatusReason string
+}
+
+// Failed reports whether the event is a resource failing, its reason is the cause, e.g. a role not being authorized
+func (e StackEvent) Failed() bool {
+	return strings.HasSuffix(e.ResourceStatus, "_FAILED")
+}
+
+func (e StackEvent) String() string {
+	line := fmt.Sprintf("%s %s %s %s", e.Timestamp.Format(time.RFC3339), e.ResourceStatus, e.ResourceType, e.LogicalResourceId)
+	if e.ResourceStatusReason != "" {
+		line += ": " + e.ResourceStatusReason
+	}
+	return line
+}
+
+// StackFailureError is a failed deploy along with the resource failures CloudFormation reported for it
+type StackFailureError struct {
+	Err    error
+	Causes []StackEvent
+}
+
+func (e *StackFailureError) Error() string {
+	var causes []string
+	for _, c := range e.Causes {
+		causes = append(causes, fmt.Sprintf("%s (%s): %s", c.LogicalResourceId, c.ResourceType, c.ResourceStatusReason))
+	}
+	return fmt.Sprintf("%v: %s", e.Err, strings.Join(causes, "; "))
+}
+
+// StackEvents lists the stack's events since the given time, oldest first
+func (w *Wrapper) StackEvents(ctx context.Context, since time.Time) ([]StackEvent, error) {
+	var resp struct {
+		StackEvents []StackEvent
+	}
+	err := w.awsCmd(ctx, &resp, "cloudformation", "describe-stack-events", "--stack-name", w.StackName())
+	if err != nil {
+		return nil, err
+	}
+
+	var events []StackEvent
+	for i := len(resp.StackEvents) - 1; i >= 0; i-- {
+		if !resp.StackEvents[i].Timestamp.Before(since) {
+			events = append(events, resp.StackEvents[i])
+		}
+	}
+	return events, nil
+}
+
+// watchStackEvents polls the stack's events into fn until the returned stop is called, which polls a last time
+func (w *Wrapper) watchStackEvents(ctx context.Context, since time.Time, fn func(StackEvent)) (stop func()) {
+	seen := make(map[string]bool)
+	poll := func(ctx context.Context) {
+		// the stack doesn't exist until the framework creates it
+		events, _ := w.StackEvents(ctx, since)
+		for _, e := range events {
+			if !seen[e.EventId] {
+				seen[e.EventId] = true
+				fn(e)
+			}
+		}
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for watchCtx.Err() == nil {
+			sleepContext(watchCtx, stackPollInterval)
+			poll(watchCtx)
+		}
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+		if ctx.Err() == nil {
+			poll(ctx)
+		}
+	}
+}
+
+func (w *Wrapper) deployRetries() int {
+	if w.KeepFailedStack {
+		return 0
+	}
+	return slsRetries
+}
+
+// execDeploy runs a stack deploy, reporting its stack events to OnStackEvent and the resource failures behind a
+// failed one, with KeepFailedStack all the events of a failed deploy are written to stderr
+func (w *Wrapper) execDeploy(ctx context.Context, args ...string) (string, error) {
+	// a minute of slack for clock skew with CloudFormation
+	start := time.Now().Add(-time.Minute)
+
+	var stop func()
+	if w.OnStackEvent != nil {
+		stop = w.watchStackEvents(ctx, start, w.OnStackEvent)
+	}
+	out, err := w.execSlsCmdRetries(ctx, w.yamlDirPath, w.deployRetries(), args...)
+	if stop != nil {
+		stop()
+	}
+	if err == nil || ctx.Err() != nil {
+		return out, err
+	}
+
+	events, eventsErr := w.StackEvents(ctx, start)
+	if eventsErr != nil {
+		if w.KeepFailedStack {
+			fmt.Fprintf(os.Stderr, "failed to get the events of stack %s: %v\n", w.StackName(), eventsErr)
+		}
+		return out, err
+	}
+	if w.KeepFailedStack {
+		fmt.Fprintf(os.Stderr, "stack %s was kept after the failed deploy, its events:\n", w.StackName())
+		for _, e := range events {
+			fmt.Fprintln(os.Stderr, e)
+		}
+	}
+
+	// resources cancelled because of another resource's failure aren't causes
+	var causes []StackEvent
+	for _, e := range events {
+		if e.Failed() && e.LogicalResourceId != w.StackName() && !strings.Contains(e.ResourceStatusReason, "cancelled") {
+			causes = append(causes, e)
+		}
+	}
+	if len(causes) == 0 {
+		return out, err
+	}
+	return out, &StackFailureError{Err: err, Causes: causes}
+}
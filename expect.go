@@ -0,0 +1,100 @@
+package sls
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonField looks up a dotted path such as "body.items.0.id" in the response, list elements are indexed by number
+func (r *InvokeResult) jsonField(path string) (interface{}, error) {
+	var v interface{}
+	err := json.Unmarshal(r.Response, &v)
+	if err != nil {
+		return nil, fmt.Errorf("response is not json: %v", err)
+	}
+	if path == "" {
+		return v, nil
+	}
+	for _, part := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			v, ok = node[part]
+			if !ok {
+				return nil, fmt.Errorf("response has no field %s", path)
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("response has no field %s", path)
+			}
+			v = node[i]
+		default:
+			return nil, fmt.Errorf("response has no field %s", path)
+		}
+	}
+	return v, nil
+}
+
+// ExpectNoFunctionError fails when the function returned an error
+func (r *InvokeResult) ExpectNoFunctionError() error {
+	if r.Error != nil {
+		return fmt.Errorf("function failed: %v", r.Error)
+	}
+	return nil
+}
+
+// ExpectStatus checks the statusCode of an api gateway or function url response
+func (r *InvokeResult) ExpectStatus(status int) error {
+	v, err := r.jsonField("statusCode")
+	if err != nil {
+		return err
+	}
+	code, ok := v.(float64)
+	if !ok || int(code) != status {
+		return fmt.Errorf("expected status %d, got %v", status, v)
+	}
+	return nil
+}
+
+// ExpectJSONField checks a field of the response against want, which is compared after a json round trip so that
+// e.g. ints match json numbers, a body holding a json string is decoded when the path goes into it
+func (r *InvokeResult) ExpectJSONField(path string, want interface{}) error {
+	got, err := r.jsonField(path)
+	if err != nil {
+		if body, ok := r.bodyResult(); ok && strings.HasPrefix(path, "body.") {
+			return body.ExpectJSONField(strings.TrimPrefix(path, "body."), want)
+		}
+		return err
+	}
+
+	data, err := json.Marshal(want)
+	if err != nil {
+		return err
+	}
+	var normalized interface{}
+	err = json.Unmarshal(data, &normalized)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(got, normalized) {
+		return fmt.Errorf("expected %s to be %v, got %v", path, normalized, got)
+	}
+	return nil
+}
+
+// bodyResult returns the json string body of a proxy response as its own result
+func (r *InvokeResult) bodyResult() (*InvokeResult, bool) {
+	v, err := r.jsonField("body")
+	if err != nil {
+		return nil, false
+	}
+	body, ok := v.(string)
+	if !ok || !json.Valid([]byte(body)) {
+		return nil, false
+	}
+	return &InvokeResult{Response: []byte(body)}, true
+}
@@ -0,0 +1,185 @@
+package sls
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// exportSchemaVersion is bumped whenever a field of the exported results changes meaning or is removed
+const exportSchemaVersion = 1
+
+var csvHeader = []string{
+	"function", "name", "runtime", "memory_size_mb", "region", "stage",
+	"index", "start", "latency_ms", "request_id", "error",
+	"duration_ms", "billed_duration_ms", "init_duration_ms", "max_memory_used_mb",
+	"error_category",
+}
+
+type exportedMeta struct {
+	Function   string    `json:"function"`
+	Name       string    `json:"name"`
+	Runtime    string    `json:"runtime"`
+	MemorySize int       `json:"memory_size_mb"`
+	Region     string    `json:"region"`
+	Stage      string    `json:"stage"`
+	Time       time.Time `json:"time"`
+}
+
+type exportedStats struct {
+	Count      int              `json:"count"`
+	Errors     int              `json:"errors"`
+	Min        float64          `json:"min_ms"`
+	Max        float64          `json:"max_ms"`
+	Mean       float64          `json:"mean_ms"`
+	P50        float64          `json:"p50_ms"`
+	StdDev     float64          `json:"stddev_ms"`
+	P90        float64          `json:"p90_ms"`
+	P95        float64          `json:"p95_ms"`
+	P99        float64          `json:"p99_ms"`
+	P999       float64          `json:"p999_ms"`
+	Wall       float64          `json:"wall_ms"`
+	Throughput float64          `json:"throughput_per_second"`
+	Histogram  []exportedBucket `json:"histogram"`
+	Outliers   []int            `json:"outliers"`
+}
+
+type exportedBucket struct {
+	From  float64 `json:"from_ms"`
+	To    float64 `json:"to_ms"`
+	Count int     `json:"count"`
+}
+
+type exportedInvocation struct {
+	Index     int       `json:"index"`
+	Start     time.Time `json:"start"`
+	Latency   float64   `json:"latency_ms"`
+	RequestID string    `json:"request_id"`
+	Error     string    `json:"error"`
+	Category  string    `json:"error_category"`
+	// the report's fields are null when lambda's REPORT line wasn't available
+	Duration       *float64 `json:"duration_ms"`
+	BilledDuration *float64 `json:"billed_duration_ms"`
+	InitDuration   *float64 `json:"init_duration_ms"`
+	MaxMemoryUsed  *int     `json:"max_memory_used_mb"`
+}
+
+type exportedResult struct {
+	SchemaVersion int                  `json:"schema_version"`
+	Meta          exportedMeta         `json:"meta"`
+	Stats         exportedStats        `json:"stats"`
+	Invocations   []exportedInvocation `json:"invocations"`
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func exportInvocation(inv Invocation) exportedInvocation {
+	e := exportedInvocation{Index: inv.Index, Start: inv.Start.UTC(), Latency: millis(inv.Latency), RequestID: inv.RequestID,
+		Category: string(inv.Category)}
+	if inv.Err != nil {
+		e.Error = inv.Err.Error()
+	}
+	if r := inv.Report; r != nil {
+		duration, billed, init, memory := millis(r.Duration), millis(r.BilledDuration), millis(r.InitDuration), r.MaxMemoryUsed
+		e.Duration, e.BilledDuration, e.InitDuration, e.MaxMemoryUsed = &duration, &billed, &init, &memory
+	}
+	return e
+}
+
+func (r *InvokeNResult) export() exportedResult {
+	s := r.Stats
+	result := exportedResult{
+		SchemaVersion: exportSchemaVersion,
+		Meta:          exportedMeta(r.Meta),
+		Stats: exportedStats{Count: s.Count, Errors: s.Errors, Min: millis(s.Min), Max: millis(s.Max), Mean: millis(s.Mean),
+			StdDev: millis(s.StdDev), P50: millis(s.P50), P90: millis(s.P90), P95: millis(s.P95), P99: millis(s.P99),
+			P999: millis(s.P999), Wall: millis(s.Wall), Throughput: s.Throughput,
+			Histogram: make([]exportedBucket, 0, len(s.Histogram)), Outliers: append([]int{}, s.Outliers...)},
+		Invocations: make([]exportedInvocation, 0, len(r.Invocations)),
+	}
+	for _, b := range s.Histogram {
+		result.Stats.Histogram = append(result.Stats.Histogram, exportedBucket{From: millis(b.From), To: millis(b.To), Count: b.Count})
+	}
+	for _, inv := range r.Invocations {
+		result.Invocations = append(result.Invocations, exportInvocation(inv))
+	}
+	return result
+}
+
+// WriteJSON writes the result with its meta and stats, durations are in milliseconds
+func (r *InvokeNResult) WriteJSON(out io.Writer) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.export())
+}
+
+func formatMillis(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', 3, 64)
+}
+
+// csvRow is an invocation's row under csvHeader
+func csvRow(m BenchmarkMeta, inv exportedInvocation) []string {
+	memory := ""
+	if inv.MaxMemoryUsed != nil {
+		memory = strconv.Itoa(*inv.MaxMemoryUsed)
+	}
+	return []string{
+		m.Function, m.Name, m.Runtime, strconv.Itoa(m.MemorySize), m.Region, m.Stage,
+		strconv.Itoa(inv.Index), inv.Start.Format(time.RFC3339Nano), formatMillis(&inv.Latency), inv.RequestID, inv.Error,
+		formatMillis(inv.Duration), formatMillis(inv.BilledDuration), formatMillis(inv.InitDuration), memory,
+		inv.Category,
+	}
+}
+
+// WriteCSV writes a row per invocation, each repeating the meta so files of several results can be concatenated
+func (r *InvokeNResult) WriteCSV(out io.Writer, header bool) error {
+	cw := csv.NewWriter(out)
+	if header {
+		err := cw.Write(csvHeader)
+		if err != nil {
+			return err
+		}
+	}
+	for _, inv := range r.export().Invocations {
+		err := cw.Write(csvRow(r.Meta, inv))
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Export writes the result to a .json or .csv file
+func (r *InvokeNResult) Export(path string) error {
+	var write func(io.Writer) error
+	switch filepath.Ext(path) {
+	case ".json":
+		write = r.WriteJSON
+	case ".csv":
+		write = func(out io.Writer) error { return r.WriteCSV(out, true) }
+	default:
+		return fmt.Errorf("unknown export format of %s, expected .json or .csv", path)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = write(f)
+	closeErr := f.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,87 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DeployError is a failed deploy along with what was done to clean up after it
+type DeployError struct {
+	Err        error
+	Cleanup    string
+	CleanupErr error
+}
+
+func (e *DeployError) Error() string {
+	if e.CleanupErr != nil {
+		return fmt.Sprintf("deploy failed: %v, cleanup (%s) failed: %v", e.Err, e.Cleanup, e.CleanupErr)
+	}
+	return fmt.Sprintf("deploy failed: %v, %s", e.Err, e.Cleanup)
+}
+
+// waitStackSettled waits out a rollback in progress, a nil stack means it doesn't exist
+func (w *Wrapper) waitStackSettled(ctx context.Context) (*stackDescription, error) {
+	timeout := w.RemovalTimeout
+	if timeout == 0 {
+		timeout = defaultRemovalTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		stack, err := w.describeStack(ctx, w.StackName())
+		if isAwsNotFound(err) {
+			return nil, nil
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("stack %s didn't settle after %v", w.StackName(), timeout)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if stack == nil || !strings.HasSuffix(stack.StackStatus, "_IN_PROGRESS") {
+			return stack, nil
+		}
+
+		sleepContext(ctx, stackPollInterval)
+	}
+}
+
+func (w *Wrapper) cleanupFailedDeploy(ctx context.Context, cause error) error {
+	deployErr := &DeployError{Err: cause}
+
+	stack, err := w.waitStackSettled(ctx)
+	if err != nil {
+		deployErr.Cleanup = "waiting for the stack's rollback"
+		deployErr.CleanupErr = err
+		return deployErr
+	}
+
+	status := ""
+	if stack != nil {
+		status = stack.StackStatus
+	}
+	switch status {
+	case "", "DELETE_COMPLETE":
+		deployErr.Cleanup = "no stack was left behind"
+	case "CREATE_FAILED", "ROLLBACK_COMPLETE", "ROLLBACK_FAILED":
+		deployErr.Cleanup = "removed the stack"
+		deployErr.CleanupErr = w.removeStack(ctx)
+		if deployErr.CleanupErr == nil {
+			deployErr.CleanupErr = w.waitStackDeleted(ctx)
+		}
+	case "UPDATE_ROLLBACK_FAILED":
+		deployErr.Cleanup = "continued the update rollback"
+		deployErr.CleanupErr = w.awsCmd(ctx, nil, "cloudformation", "continue-update-rollback", "--stack-name", w.StackName())
+		if deployErr.CleanupErr == nil {
+			stack, deployErr.CleanupErr = w.waitStackSettled(ctx)
+		}
+		if deployErr.CleanupErr == nil && stack != nil && stack.StackStatus != "UPDATE_ROLLBACK_COMPLETE" {
+			deployErr.CleanupErr = fmt.Errorf("stack %s is %s", w.StackName(), stack.StackStatus)
+		}
+	default:
+		deployErr.Cleanup = fmt.Sprintf("stack was left %s", status)
+	}
+	return deployErr
+}
@@ -0,0 +1,58 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+)
+
+// DeployFunction updates the code of a single function, only the builders of its runtime are run
+func (w *Wrapper) DeployFunction(ctx context.Context, name string) error {
+	if _, ok := w.stack.Functions[name]; !ok {
+		return fmt.Errorf("function %s is not defined in %s", name, w.configName)
+	}
+
+	unlock, err := w.lockStack(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	defer w.lockDir()()
+
+	_, err = w.deployFunctions(ctx, []string{name})
+	return err
+}
+
+// deployFunctions builds the functions' runtimes once and updates each function's code
+func (w *Wrapper) deployFunctions(ctx context.Context, names []string) ([]BuildResult, error) {
+	functions := make(map[string]bool)
+	for _, name := range names {
+		functions[name] = true
+	}
+	results, err := w.build(ctx, functions)
+	if err != nil {
+		return nil, err
+	}
+	err = w.validateArtifactSizes(results)
+	if err != nil {
+		return nil, err
+	}
+
+	deployer, done, err := w.packaged(results)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	defer w.cacheInfo(nil)
+	for _, name := range names {
+		args := []string{"deploy", "function", "-f", name}
+		if w.Force {
+			args = append(args, "--force")
+		}
+		_, err = deployer.execSlsCmdRetries(ctx, w.yamlDirPath, w.deployRetries(), args...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,154 @@
+package sls
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	PathFunctionURL = "url"
+	PathHTTPAPI     = "httpApi"
+	PathRESTAPI     = "rest"
+)
+
+// pathParamRe matches the {param} placeholders of the endpoints' paths
+var pathParamRe = regexp.MustCompile(`\{[^}]+\}`)
+
+// PathLatency is the latency of one way of reaching a function, Delta is its median minus the baseline's, which is
+// the function url when the function has one
+type PathLatency struct {
+	Path   string
+	Method string
+	URL    string
+	Stats  LatencyStats
+	Delta  time.Duration
+}
+
+type invocationPath struct {
+	path   string
+	method string
+	url    string
+}
+
+// invocationPaths finds the function url and the first endpoint of each gateway kind the function is exposed at
+func (w *Wrapper) invocationPaths(ctx context.Context, key string) ([]invocationPath, error) {
+	info, err := w.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var paths []invocationPath
+	for _, e := range info.Endpoints {
+		if e.Function == key {
+			paths = append(paths, invocationPath{path: PathFunctionURL, method: http.MethodPost, url: e.URL})
+			break
+		}
+	}
+
+	stagePrefix := "/" + w.resolvedStage()
+	found := map[string]bool{}
+	for _, event := range httpEvents(w.stack.Functions[key]) {
+		path := PathRESTAPI
+		if event.kind == "httpApi" {
+			path = PathHTTPAPI
+		}
+		if found[path] {
+			continue
+		}
+		for _, e := range info.Endpoints {
+			u, err := url.Parse(e.URL)
+			if err != nil || !event.matches(e) {
+				continue
+			}
+			// rest apis are served under the stage, http apis under their default stage
+			rest := u.Path == stagePrefix || strings.HasPrefix(u.Path, stagePrefix+"/")
+			if rest != (path == PathRESTAPI) {
+				continue
+			}
+			method := strings.ToUpper(e.Method)
+			if method == "ANY" || method == "*" {
+				method = http.MethodPost
+			}
+			paths = append(paths, invocationPath{path: path, method: method, url: pathParamRe.ReplaceAllString(e.URL, "sample")})
+			found[path] = true
+			break
+		}
+	}
+	return paths, nil
+}
+
+func timedRequest(ctx context.Context, method string, url string, payload []byte) (time.Duration, error) {
+	var body io.Reader
+	if method != http.MethodGet && method != http.MethodHead {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_, err = io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	latency := time.Since(start)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode >= 300 {
+		return latency, fmt.Errorf("%s %s returned %s", method, url, resp.Status)
+	}
+	return latency, nil
+}
+
+// CompareInvocationPaths requests the function n times through each of its function url, http api and rest api
+// endpoints, after a request each to warm them up, the urls have to allow unauthenticated requests
+func (w *Wrapper) CompareInvocationPaths(ctx context.Context, key string, payload []byte, n int) ([]PathLatency, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	paths, err := w.invocationPaths(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("function %s has no function url or http endpoint", key)
+	}
+
+	var results []PathLatency
+	for _, p := range paths {
+		_, err := timedRequest(ctx, p.method, p.url, payload)
+		if err != nil {
+			return nil, fmt.Errorf("warming up the %s endpoint of %s: %v", p.path, key, err)
+		}
+
+		invocations := make([]Invocation, n)
+		start := time.Now()
+		for i := range invocations {
+			invocations[i].Index, invocations[i].Start = i, time.Now()
+			invocations[i].Latency, invocations[i].Err = timedRequest(ctx, p.method, p.url, payload)
+			invocations[i].Category = ClassifyError(invocations[i].Err)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+		}
+		results = append(results, PathLatency{Path: p.path, Method: p.method, URL: p.url, Stats: latencyStats(invocations, time.Since(start))})
+	}
+
+	for i := range results {
+		results[i].Delta = results[i].Stats.P50 - results[0].Stats.P50
+	}
+	return results, nil
+}
@@ -0,0 +1,139 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type GCOptions struct {
+	// MaxAge is how old a suffix has to be before its stack is removed
+	MaxAge time.Duration
+	DryRun bool
+}
+
+type OrphanedStack struct {
+	StackName string
+	Suffix    string
+	CreatedAt time.Time
+}
+
+type stackSummary struct {
+	StackName    string
+	StackStatus  string
+	CreationTime time.Time
+}
+
+func (w *Wrapper) listStacks(ctx context.Context) ([]stackSummary, error) {
+	var resp struct {
+		StackSummaries []stackSummary
+	}
+	err := w.awsCmd(ctx, &resp, "cloudformation", "list-stacks")
+	if err != nil {
+		return nil, err
+	}
+
+	var stacks []stackSummary
+	for _, s := range resp.StackSummaries {
+		if s.StackStatus != "DELETE_COMPLETE" {
+			stacks = append(stacks, s)
+		}
+	}
+	return stacks, nil
+}
+
+// servicePattern matches the service names of every suffix, capturing the suffix
+func (w *Wrapper) servicePattern() (string, error) {
+	service := w.stack.StackId
+	if !strings.Contains(service, "${opt:suffix}") {
+		return "", fmt.Errorf("service %s has no ${opt:suffix}, there are no suffixed stacks to collect", service)
+	}
+	parts := strings.Split(service, "${opt:suffix}")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return strings.Join(parts, "([A-Za-z0-9]+)"), nil
+}
+
+// stackNameRe matches the stack names of every suffix of this service and stage
+func (w *Wrapper) stackNameRe() (*regexp.Regexp, error) {
+	pattern, err := w.servicePattern()
+	if err != nil {
+		return nil, err
+	}
+	return regexp.Compile("^" + pattern + "-" + regexp.QuoteMeta(w.resolvedStage()) + "$")
+}
+
+// suffixTime reads the creation time from the default nanosecond timestamp suffixes
+func suffixTime(suffix string, fallback time.Time) time.Time {
+	nanos, err := strconv.ParseInt(suffix, 10, 64)
+	if err != nil || nanos < int64(time.Second) {
+		return fallback
+	}
+	return time.Unix(0, nanos)
+}
+
+func (w *Wrapper) OrphanedStacks(ctx context.Context, maxAge time.Duration) ([]OrphanedStack, error) {
+	err := w.requireAWS("finding orphaned stacks")
+	if err != nil {
+		return nil, err
+	}
+	re, err := w.stackNameRe()
+	if err != nil {
+		return nil, err
+	}
+	stacks, err := w.listStacks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []OrphanedStack
+	for _, s := range stacks {
+		m := re.FindStringSubmatch(s.StackName)
+		if m == nil || m[1] == w.suffix {
+			continue
+		}
+		created := suffixTime(m[1], s.CreationTime)
+		if time.Since(created) < maxAge {
+			continue
+		}
+		orphans = append(orphans, OrphanedStack{StackName: s.StackName, Suffix: m[1], CreatedAt: created})
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].CreatedAt.Before(orphans[j].CreatedAt) })
+	return orphans, nil
+}
+
+// GC removes the stacks of suffixes older than MaxAge, as left behind by crashed runs
+func (w *Wrapper) GC(ctx context.Context, opts GCOptions) ([]OrphanedStack, error) {
+	orphans, err := w.OrphanedStacks(ctx, opts.MaxAge)
+	if err != nil || opts.DryRun {
+		return orphans, err
+	}
+
+	var removed []OrphanedStack
+	var failures []string
+	for _, orphan := range orphans {
+		err = w.removeSuffix(ctx, orphan.Suffix)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", orphan.StackName, err))
+			continue
+		}
+		removed = append(removed, orphan)
+	}
+	if len(failures) > 0 {
+		return removed, fmt.Errorf("failed to remove orphaned stacks:\n  %s", strings.Join(failures, "\n  "))
+	}
+	return removed, nil
+}
+
+func (w *Wrapper) removeSuffix(ctx context.Context, suffix string) error {
+	other, err := w.WithSuffix(suffix)
+	if err != nil {
+		return err
+	}
+	return other.RemoveStackContext(ctx)
+}
@@ -2,4 +2,4 @@ module github.com/nuweba/sls
 
 go 1.12
 
-require gopkg.in/yaml.v2 v2.2.2 // indirect
+require gopkg.in/yaml.v2 v2.2.2
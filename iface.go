@@ -0,0 +1,21 @@
+package sls
+
+import "context"
+
+// Deployer is the part of Wrapper that manages the stack, for code that wants to swap it in tests
+type Deployer interface {
+	DeployStackContext(ctx context.Context) (*DeployResult, error)
+	DeployFunction(ctx context.Context, name string) error
+	RemoveStackContext(ctx context.Context) error
+}
+
+// Invoker is the part of Wrapper that invokes deployed functions
+type Invoker interface {
+	Invoke(ctx context.Context, key string, payload []byte) (*InvokeResult, error)
+	InvokeAsync(ctx context.Context, key string, payload []byte, opts *AsyncOptions) (*AsyncResult, error)
+}
+
+var (
+	_ Deployer = (*Wrapper)(nil)
+	_ Invoker  = (*Wrapper)(nil)
+)
@@ -0,0 +1,117 @@
+package sls
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const dockerfileName = "Dockerfile"
+
+func imageOpt(key string) string {
+	return "image-" + key
+}
+
+func imageDigest(image string) string {
+	i := strings.Index(image, "@sha256:")
+	if i < 0 {
+		return ""
+	}
+	return image[i+len("@sha256:"):]
+}
+
+// image functions are built from a Dockerfile in the directory named after the function key
+func (w *Wrapper) imageFunctions() []string {
+	var keys []string
+	for key, f := range w.stack.Functions {
+		if f.Image != "" && fileExists(filepath.Join(w.yamlDirPath, key, dockerfileName)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (w *Wrapper) imageJobs(functions map[string]bool) []buildJob {
+	var jobs []buildJob
+	for _, key := range w.imageFunctions() {
+		key := key
+		if functions != nil && !functions[key] {
+			continue
+		}
+		jobs = append(jobs, buildJob{
+			name:   "image:" + key,
+			weight: 2,
+			build:  func(log *buildLog) error { return w.buildImage(log, key) },
+		})
+	}
+	return jobs
+}
+
+func (w *Wrapper) imageRepository() string {
+	if w.ImageRepository != "" {
+		return w.ImageRepository
+	}
+	if p, ok := w.Provider().(ImageProvider); ok {
+		return p.ImageRepository(w)
+	}
+	return ""
+}
+
+func (w *Wrapper) buildImage(log *buildLog, key string) error {
+	repository := w.imageRepository()
+	if repository == "" {
+		return errors.New("function " + key + " is image based but no ImageRepository is set")
+	}
+	tag := repository + ":" + key + "-" + w.suffix
+	dir := filepath.Join(w.yamlDirPath, key)
+
+	_, err := log.execCmd(w, []string{}, dir, "docker", "build", "-t", tag, ".")
+	if err != nil {
+		return err
+	}
+	if p, ok := w.Provider().(ImageProvider); ok {
+		err = p.RegistryLogin(log.ctx, w, repository, log)
+	} else {
+		err = w.ecrLogin(log)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = log.execCmd(w, []string{}, dir, "docker", "push", tag)
+	if err != nil {
+		return err
+	}
+
+	image, err := w.execCmdOutput(log.ctx, []string{}, dir, ioutil.Discard, log, "docker", "inspect", "--format", "{{index .RepoDigests 0}}", tag)
+	if err != nil {
+		return err
+	}
+	log.addImage(image, key)
+	return nil
+}
+
+func (w *Wrapper) ecrLogin(log *buildLog) error {
+	registry := strings.SplitN(w.ImageRepository, "/", 2)[0]
+	// the password must not end up in the build log
+	password, err := w.execCmdOutput(log.ctx, []string{}, w.yamlDirPath, ioutil.Discard, log, "aws", "ecr", "get-login-password", "--region", w.Region())
+	if err != nil {
+		return err
+	}
+	_, err = w.execCmdIO(log.ctx, []string{}, w.yamlDirPath, strings.NewReader(password), log, log, "docker", "login", "--username", "AWS", "--password-stdin", registry)
+	return err
+}
+
+// injectImages passes the pushed digests as --image-<function> so the config can reference ${opt:image-<function>}
+func (w *Wrapper) injectImages(results []BuildResult) {
+	for _, r := range results {
+		if r.Image == "" {
+			continue
+		}
+		for _, key := range r.Functions {
+			w.Opts[imageOpt(key)] = r.Image
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type Endpoint struct {
+	Method string
+	URL    string
+	// Function is set on function urls, which are listed by function key
+	Function string
+}
+
+type ServiceInfo struct {
+	Service string
+	Stage   string
+	Region  string
+	// Namespace is the namespace of providers that have them, such as openwhisk
+	Namespace string
+	Stack     string
+	Resources int
+	Endpoints []Endpoint
+	// Functions maps the function keys to their deployed names
+	Functions map[string]string
+	Layers    map[string]string
+	// APIKeys maps the names of the api keys the stack created to their values
+	APIKeys map[string]string
+	Outputs map[string]string
+}
+
+func parseEndpoint(s string) Endpoint {
+	parts := strings.SplitN(s, " - ", 2)
+	if len(parts) == 2 {
+		return Endpoint{Method: strings.TrimSpace(parts[0]), URL: strings.TrimSpace(parts[1])}
+	}
+	if i := strings.Index(s, ": "); i > 0 && strings.HasPrefix(strings.TrimSpace(s[i+2:]), "http") {
+		return Endpoint{Function: strings.TrimSpace(s[:i]), URL: strings.TrimSpace(s[i+2:])}
+	}
+	return Endpoint{URL: strings.TrimSpace(s)}
+}
+
+func splitKeyValue(line string) (string, string, bool) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]), true
+}
+
+// stripSize removes the package size newer framework versions append, e.g. "svc-dev-hello (1.2 kB)"
+func stripSize(s string) string {
+	if i := strings.LastIndex(s, " ("); i > 0 && strings.HasSuffix(s, ")") {
+		return s[:i]
+	}
+	return s
+}
+
+// infoKeys are the keys of the service information, sls info output is read from the first of them on
+var infoKeys = map[string]bool{
+	"service":   true,
+	"stage":     true,
+	"region":    true,
+	"stack":     true,
+	"resources": true,
+	"endpoint":  true,
+	"endpoints": true,
+	"functions": true,
+	"layers":    true,
+	"api keys":  true,
+}
+
+func parseServiceInfo(out string) *ServiceInfo {
+	info := &ServiceInfo{
+		Functions: make(map[string]string),
+		Layers:    make(map[string]string),
+		APIKeys:   make(map[string]string),
+		Outputs:   make(map[string]string),
+	}
+
+	section := ""
+	inInfo := false
+	for _, line := range slsLines(out) {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			continue
+		case trimmed == "Service Information":
+			inInfo = true
+			section = ""
+			continue
+		case trimmed == "Stack Outputs" || trimmed == "Stack Outputs:":
+			inInfo = true
+			section = "outputs"
+			continue
+		case !inInfo:
+			// newer versions print no header, the info starts at its first key
+			key, _, ok := splitKeyValue(trimmed)
+			if line == trimmed && ok && infoKeys[key] {
+				inInfo = true
+				break
+			}
+			continue
+		}
+
+		indented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+		if indented && section != "outputs" {
+			if trimmed == "None" {
+				continue
+			}
+			switch section {
+			case "endpoints":
+				info.Endpoints = append(info.Endpoints, parseEndpoint(trimmed))
+			case "functions":
+				if k, v, ok := splitKeyValue(trimmed); ok {
+					info.Functions[k] = stripSize(v)
+				}
+			case "layers":
+				if k, v, ok := splitKeyValue(trimmed); ok {
+					info.Layers[k] = v
+				}
+			case "api keys":
+				// newer versions append the key's description
+				if k, v, ok := splitKeyValue(trimmed); ok {
+					info.APIKeys[k] = strings.SplitN(v, " - ", 2)[0]
+				}
+			}
+			continue
+		}
+
+		key, value, ok := splitKeyValue(trimmed)
+		if !ok {
+			continue
+		}
+		if section == "outputs" {
+			info.Outputs[key] = value
+			continue
+		}
+
+		section = ""
+		switch key {
+		case "service":
+			info.Service = value
+		case "stage":
+			info.Stage = value
+		case "region":
+			info.Region = value
+		case "stack":
+			info.Stack = value
+		case "resources":
+			info.Resources, _ = strconv.Atoi(value)
+		case "endpoint":
+			info.Endpoints = append(info.Endpoints, parseEndpoint(value))
+		case "endpoints", "functions", "layers", "api keys":
+			section = key
+		}
+	}
+	return info
+}
+
+// functionLogicalId follows the framework's naming of the function resources in the CloudFormation template
+func functionLogicalId(key string) string {
+	if key == "" {
+		return ""
+	}
+	id := strings.ToUpper(key[:1]) + key[1:]
+	id = strings.Replace(id, "-", "Dash", -1)
+	id = strings.Replace(id, "_", "Underscore", -1)
+	return id
+}
+
+func (info *ServiceInfo) functionQualifiedArn(key string) string {
+	return info.Outputs[functionLogicalId(key)+"LambdaFunctionQualifiedArn"]
+}
+
+func unqualifiedArn(arn string) string {
+	// arn:aws:lambda:region:account:function:name:version
+	parts := strings.Split(arn, ":")
+	if len(parts) == 8 {
+		return strings.Join(parts[:7], ":")
+	}
+	return arn
+}
+
+type infoCache struct {
+	mu    sync.Mutex
+	infos map[string]*ServiceInfo
+}
+
+func newInfoCache() *infoCache {
+	return &infoCache{infos: make(map[string]*ServiceInfo)}
+}
+
+// Info runs sls info, the parsed result is cached for the wrapper's suffix until the stack is deployed or removed again
+func (w *Wrapper) Info(ctx context.Context) (*ServiceInfo, error) {
+	w.infoCache.mu.Lock()
+	info, ok := w.infoCache.infos[w.suffix]
+	w.infoCache.mu.Unlock()
+	if ok {
+		return info, nil
+	}
+
+	out, err := w.execSlsCmd(ctx, w.yamlDirPath, "info", "--verbose")
+	if err != nil {
+		return nil, err
+	}
+	info, err = w.parseInfo(ctx, out)
+	if err != nil {
+		return nil, err
+	}
+	w.cacheInfo(info)
+	return info, nil
+}
+
+func (w *Wrapper) cacheInfo(info *ServiceInfo) {
+	w.infoCache.mu.Lock()
+	defer w.infoCache.mu.Unlock()
+	if info == nil {
+		delete(w.infoCache.infos, w.suffix)
+		return
+	}
+	w.infoCache.infos[w.suffix] = info
+}
+
+// FunctionARN maps a function key to the unqualified arn of its deployed function
+func (w *Wrapper) FunctionARN(ctx context.Context, key string) (string, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return "", fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	info, err := w.Info(ctx)
+	if err != nil {
+		return "", err
+	}
+	if qualified := info.functionQualifiedArn(key); qualified != "" {
+		return unqualifiedArn(qualified), nil
+	}
+
+	// the qualified arn output is missing when versionFunctions is disabled
+	name := w.functionName(key)
+	if deployed, ok := info.Functions[key]; ok {
+		name = deployed
+	}
+	config, err := w.getFunctionConfiguration(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	return config.FunctionArn, nil
+}
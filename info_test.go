@@ -0,0 +1,31 @@
+package sls
+
+import "testing"
+
+func TestParseServiceInfoWithoutHeader(t *testing.T) {
+	out := "Running \"serverless\" from node_modules\n" +
+		"service: hello-k3j9x\n" +
+		"stage: dev\n" +
+		"region: us-east-1\n" +
+		"stack: hello-k3j9x-dev\n" +
+		"endpoint: GET - https://a1b2c3d4e5.execute-api.us-east-1.amazonaws.com/dev/hello\n" +
+		"functions:\n" +
+		"  hello: hello-k3j9x-dev-hello (1.4 kB)\n" +
+		"\n" +
+		"Stack Outputs:\n" +
+		"  ServiceEndpoint: https://a1b2c3d4e5.execute-api.us-east-1.amazonaws.com/dev\n"
+
+	info := parseServiceInfo(out)
+	if info.Service != "hello-k3j9x" || info.Stage != "dev" || info.Region != "us-east-1" || info.Stack != "hello-k3j9x-dev" {
+		t.Fatalf("unexpected service information %+v", info)
+	}
+	if len(info.Endpoints) != 1 || info.Endpoints[0].Method != "GET" {
+		t.Errorf("unexpected endpoints %+v", info.Endpoints)
+	}
+	if info.Functions["hello"] != "hello-k3j9x-dev-hello" {
+		t.Errorf("unexpected functions %v", info.Functions)
+	}
+	if info.Outputs["ServiceEndpoint"] != "https://a1b2c3d4e5.execute-api.us-east-1.amazonaws.com/dev" {
+		t.Errorf("unexpected outputs %v", info.Outputs)
+	}
+}
@@ -0,0 +1,230 @@
+package sls
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// logSeparatorRe matches the line sls invoke --log writes between the response and the log tail
+var logSeparatorRe = regexp.MustCompile(`^-{10,}$`)
+
+// FunctionError is the error a function returned, as serialized by the lambda runtimes
+type FunctionError struct {
+	Type       string   `json:"errorType"`
+	Message    string   `json:"errorMessage"`
+	StackTrace []string `json:"stackTrace"`
+}
+
+func (e *FunctionError) Error() string {
+	if e.Type == "" {
+		return e.Message
+	}
+	return e.Type + ": " + e.Message
+}
+
+type LocalInvokeOptions struct {
+	// Docker runs the handler in the runtime's lambda image, DockerArgs are passed to docker run
+	Docker     bool
+	DockerArgs []string
+	Env        map[string]string
+}
+
+type InvokeResult struct {
+	Response []byte
+	// RequestID is read from the logs, so remote invokes only know it when InvokeLogs is set
+	RequestID string
+	Report    *Report
+	// Error is set when the function failed, the same error is returned alongside the result
+	Error *FunctionError
+	// Category classifies Error, a failure to invoke at all is only returned as the error
+	Category ErrorCategory
+	// Logs is the log tail, remote invokes only request it when InvokeLogs is set
+	Logs string
+}
+
+// parseFunctionError reads the runtimes' {"errorMessage": ...} responses
+func parseFunctionError(response []byte) *FunctionError {
+	var raw map[string]json.RawMessage
+	if json.Unmarshal(response, &raw) != nil {
+		return nil
+	}
+	if _, ok := raw["errorMessage"]; !ok {
+		return nil
+	}
+
+	fe := &FunctionError{}
+	json.Unmarshal(raw["errorType"], &fe.Type)
+	json.Unmarshal(raw["errorMessage"], &fe.Message)
+	// the node runtime reports the trace as a list of lines, python as a list of frames
+	if json.Unmarshal(raw["stackTrace"], &fe.StackTrace) != nil {
+		var frames [][]interface{}
+		json.Unmarshal(raw["stackTrace"], &frames)
+		for _, f := range frames {
+			fe.StackTrace = append(fe.StackTrace, strings.TrimSpace(fmt.Sprintln(f...)))
+		}
+	}
+	return fe
+}
+
+// parseInvokeOutput splits the output of sls invoke into the response and the log tail, dropping the framework's logs
+func parseInvokeOutput(out string) *InvokeResult {
+	var response, logs []string
+	inLogs := false
+	for _, line := range strings.Split(ansiRe.ReplaceAllString(out, ""), "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case inLogs:
+			logs = append(logs, line)
+		case logSeparatorRe.MatchString(strings.TrimSpace(line)):
+			inLogs = true
+		case strings.HasPrefix(line, "Serverless: "):
+		default:
+			response = append(response, line)
+		}
+	}
+
+	result := &InvokeResult{
+		Response: []byte(strings.TrimSpace(strings.Join(response, "\n"))),
+		Logs:     strings.TrimSpace(strings.Join(logs, "\n")),
+	}
+	result.RequestID = requestIDFromLogs(result.Logs)
+	result.Report = reportFromLogs(result.Logs)
+	result.Error = parseFunctionError(result.Response)
+	if result.Error != nil {
+		result.Category = ClassifyError(result.Error)
+	}
+	return result
+}
+
+// parseLocalInvokeOutput splits the output of sls invoke local, where the handler's logs come before the response
+func parseLocalInvokeOutput(out string) *InvokeResult {
+	var lines []string
+	for _, line := range strings.Split(ansiRe.ReplaceAllString(out, ""), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if !strings.HasPrefix(line, "Serverless: ") {
+			lines = append(lines, line)
+		}
+	}
+
+	result := &InvokeResult{Response: []byte(strings.TrimSpace(strings.Join(lines, "\n")))}
+	// the response is the longest tail of the output that is a json document
+	for i := range lines {
+		tail := strings.TrimSpace(strings.Join(lines[i:], "\n"))
+		if tail != "" && json.Valid([]byte(tail)) {
+			result.Response = []byte(tail)
+			result.Logs = strings.TrimSpace(strings.Join(lines[:i], "\n"))
+			break
+		}
+	}
+	result.RequestID = requestIDFromLogs(result.Logs)
+	result.Report = reportFromLogs(result.Logs)
+	result.Error = parseFunctionError(result.Response)
+	if result.Error != nil {
+		result.Category = ClassifyError(result.Error)
+	}
+	return result
+}
+
+func writePayload(payload []byte) (string, error) {
+	payloadFile, err := ioutil.TempFile("", "sls-payload-")
+	if err != nil {
+		return "", err
+	}
+	_, err = payloadFile.Write(payload)
+	payloadFile.Close()
+	if err != nil {
+		os.Remove(payloadFile.Name())
+		return "", err
+	}
+	return payloadFile.Name(), nil
+}
+
+func (w *Wrapper) Invoke(ctx context.Context, key string, payload []byte) (*InvokeResult, error) {
+	path, err := writePayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+	return w.InvokeFile(ctx, key, path)
+}
+
+// InvokeLocal runs the handler on this machine with sls invoke local, or in the runtime's docker image when
+// LocalInvoke.Docker is set, nothing has to be deployed
+func (w *Wrapper) InvokeLocal(ctx context.Context, key string, payload []byte) (*InvokeResult, error) {
+	path, err := writePayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+	return w.InvokeLocalFile(ctx, key, path)
+}
+
+// InvokeLocalFile runs the handler locally with the event in a json file
+func (w *Wrapper) InvokeLocalFile(ctx context.Context, key string, path string) (*InvokeResult, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"invoke", "local", "-f", key, "--path", path}
+	if w.LocalInvoke.Docker {
+		args = append(args, "--docker")
+	}
+	for _, arg := range w.LocalInvoke.DockerArgs {
+		args = append(args, "--docker-arg", arg)
+	}
+	env := make([]string, 0, len(w.LocalInvoke.Env))
+	for k, v := range w.LocalInvoke.Env {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+	for _, e := range env {
+		args = append(args, "--env", e)
+	}
+
+	out, err := w.execSlsCmdRetries(ctx, w.yamlDirPath, 0, args...)
+	result := parseLocalInvokeOutput(out)
+	if result.Error != nil {
+		return result, result.Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// InvokeFile invokes the function with the event in a json file, sls invoke runs once since retrying would repeat
+// the function's side effects
+func (w *Wrapper) InvokeFile(ctx context.Context, key string, path string) (*InvokeResult, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"invoke", "-f", key, "--path", path}
+	if w.InvokeLogs {
+		args = append(args, "--log")
+	}
+	out, err := w.execSlsCmdRetries(ctx, w.yamlDirPath, 0, args...)
+	result := parseInvokeOutput(out)
+	if result.Error != nil {
+		return result, result.Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
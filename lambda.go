@@ -0,0 +1,136 @@
+package sls
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// lambdaClient keeps connections to lambda open, so concurrent benchmark invokes don't pay for new tls handshakes
+var lambdaClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConns:        1000,
+		MaxIdleConnsPerHost: 1000,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
+type lambdaInvokeOutput struct {
+	StatusCode      int
+	FunctionError   string
+	LogResult       string
+	ExecutedVersion string
+	// RequestID is the invocation's request id, which its START and REPORT log lines carry
+	RequestID string
+	Payload   []byte `json:"-"`
+	// Latency is the round trip of the invoke request alone, without signing it
+	Latency time.Duration `json:"-"`
+}
+
+func (o *lambdaInvokeOutput) logTail() string {
+	data, err := base64.StdEncoding.DecodeString(o.LogResult)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// lambdaError is an error response of the lambda api, such as a TooManyRequestsException when throttled
+type lambdaError struct {
+	Function   string
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *lambdaError) Error() string {
+	return fmt.Sprintf("lambda invoke %s: %d %s: %s", e.Function, e.StatusCode, e.Type, e.Message)
+}
+
+func (w *Wrapper) lambdaEndpoint() string {
+	if w.LocalStackEndpoint != "" {
+		return strings.TrimSuffix(w.LocalStackEndpoint, "/")
+	}
+	region := w.Region()
+	if strings.HasPrefix(region, "cn-") {
+		return "https://lambda." + region + ".amazonaws.com.cn"
+	}
+	return "https://lambda." + region + ".amazonaws.com"
+}
+
+// lambdaInvoke calls the lambda api directly, unlike sls invoke it reports the function error type and status code,
+// the request is signed and sent in process without retries, so throttles are reported rather than retried and the
+// latency doesn't include starting a cli
+func (w *Wrapper) lambdaInvoke(ctx context.Context, name string, payload []byte, invocationType string, logTail bool) (*lambdaInvokeOutput, error) {
+	err := w.requireAWS("invoking through the lambda api")
+	if err != nil {
+		return nil, err
+	}
+	creds, err := w.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u := w.lambdaEndpoint() + "/2015-03-31/functions/" + url.PathEscape(name) + "/invocations"
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Amz-Invocation-Type", invocationType)
+	if logTail {
+		req.Header.Set("X-Amz-Log-Type", "Tail")
+	}
+	signRequest(req, payload, creds, w.Region(), "lambda", time.Now())
+
+	start := time.Now()
+	resp, err := lambdaClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	latency := time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		// the body's message is "message" or "Message" depending on the error, which json matches either way
+		var apiErr struct {
+			Type    string
+			Message string
+		}
+		json.Unmarshal(body, &apiErr)
+		e := &lambdaError{Function: name, StatusCode: resp.StatusCode, Type: resp.Header.Get("X-Amzn-ErrorType"), Message: apiErr.Message}
+		if i := strings.Index(e.Type, ":"); i >= 0 {
+			e.Type = e.Type[:i]
+		}
+		if e.Type == "" {
+			e.Type = apiErr.Type
+		}
+		if e.Message == "" {
+			e.Message = strings.TrimSpace(string(body))
+		}
+		return nil, e
+	}
+
+	return &lambdaInvokeOutput{
+		StatusCode:      resp.StatusCode,
+		FunctionError:   resp.Header.Get("X-Amz-Function-Error"),
+		LogResult:       resp.Header.Get("X-Amz-Log-Result"),
+		ExecutedVersion: resp.Header.Get("X-Amz-Executed-Version"),
+		RequestID:       resp.Header.Get("X-Amzn-RequestId"),
+		Payload:         body,
+		Latency:         latency,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package sls
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+func (w *Wrapper) ListLayersFromYaml() Layers {
+	return w.stack.Layers
+}
+
+func (w *Wrapper) layerJobs() []buildJob {
+	names := make([]string, 0, len(w.stack.Layers))
+	for name := range w.stack.Layers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var jobs []buildJob
+	for _, name := range names {
+		name := name
+		layer := w.stack.Layers[name]
+		jobs = append(jobs, buildJob{
+			name:  "layer:" + name,
+			build: func(log *buildLog) error { return w.buildLayer(log, name, layer) },
+		})
+	}
+	return jobs
+}
+
+// buildLayer installs the layer's python requirements into the build dir, they're zipped with the layer path into
+// its package.artifact, or into an artifact of its own when it has none
+func (w *Wrapper) buildLayer(log *buildLog, name string, layer LayerMeta) error {
+	if layer.Path == "" {
+		return nil
+	}
+	layerPath := filepath.Join(w.yamlDirPath, layer.Path)
+	buildDir := w.outputPath(layer.Path, "dist")
+
+	nodePath := filepath.Join(layerPath, "nodejs")
+	if fileExists(filepath.Join(nodePath, "package.json")) {
+		_, err := log.execCmd(w, []string{}, nodePath, "npm", "install", "--production")
+		if err != nil {
+			return err
+		}
+	}
+
+	var packages []artifactFile
+	pythonPath := filepath.Join(layerPath, "python")
+	if fileExists(filepath.Join(pythonPath, "requirements.txt")) {
+		packagesPath := filepath.Join(buildDir, "python")
+		err := pipInstall(log, w, pythonPath, packagesPath)
+		if err != nil {
+			return err
+		}
+		// the python runtime loads the layer's packages from its python dir
+		packages, err = dirArtifactFiles(packagesPath, "python")
+		if err != nil {
+			return err
+		}
+	}
+
+	// without an explicit artifact or installed requirements the framework zips the layer path by itself
+	if layer.Package.Artifact == "" && len(packages) == 0 {
+		return nil
+	}
+	files, err := dirSourceFiles(layerPath, "", buildDir)
+	if err != nil {
+		return err
+	}
+	artifact := filepath.Join(buildDir, name+".zip")
+	if layer.Package.Artifact != "" {
+		artifact = w.artifactRef(layer.Package.Artifact)
+	}
+	err = zipArtifact(artifact, append(files, packages...), w.artifactModTime())
+	if err != nil {
+		return err
+	}
+	log.addArtifact(artifact)
+	return nil
+}
+
+func fileExists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
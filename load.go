@@ -0,0 +1,128 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LoadPattern is the rate of invocations per second at a point of a load test
+type LoadPattern func(elapsed time.Duration) float64
+
+func ConstantLoad(rps float64) LoadPattern {
+	return func(time.Duration) float64 { return rps }
+}
+
+// RampLoad goes linearly from one rate to another over the given time and stays at the last
+func RampLoad(from, to float64, over time.Duration) LoadPattern {
+	return func(elapsed time.Duration) float64 {
+		if elapsed >= over {
+			return to
+		}
+		return from + (to-from)*float64(elapsed)/float64(over)
+	}
+}
+
+// StepLoad starts at a rate and adds step to it every interval
+func StepLoad(start, step float64, every time.Duration) LoadPattern {
+	return func(elapsed time.Duration) float64 {
+		return start + step*float64(elapsed/every)
+	}
+}
+
+// SpikeLoad holds a base rate except for a peak rate during length from at
+func SpikeLoad(base, peak float64, at, length time.Duration) LoadPattern {
+	return func(elapsed time.Duration) float64 {
+		if elapsed >= at && elapsed < at+length {
+			return peak
+		}
+		return base
+	}
+}
+
+// LoadBucket holds the invocations started in one second of a load test
+type LoadBucket struct {
+	Second      int
+	Target      float64
+	Invocations []Invocation
+	Stats       LatencyStats
+}
+
+type LoadResult struct {
+	Meta    BenchmarkMeta
+	Buckets []LoadBucket
+	Stats   LatencyStats
+}
+
+// Load invokes the function at the pattern's rate for the duration, invocations are started on schedule whether or
+// not earlier ones returned, so a slow function shows up as latency rather than a lower rate
+func (w *Wrapper) Load(ctx context.Context, key string, payload []byte, pattern LoadPattern, duration time.Duration) (*LoadResult, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	meta, err := w.benchmarkMeta(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := w.newSampleWriter(*meta)
+	defer samples.flush()
+
+	seconds := int(math.Ceil(duration.Seconds()))
+	buckets := make([]LoadBucket, seconds)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	index := 0
+	carry := 0.0
+	for s := 0; s < seconds && ctx.Err() == nil; s++ {
+		rate := pattern(time.Duration(s)*time.Second + time.Second/2)
+		buckets[s] = LoadBucket{Second: s, Target: rate}
+		// fractional rates carry over to the next second
+		n := int(rate + carry)
+		carry = rate + carry - float64(n)
+
+		for i := 0; i < n && ctx.Err() == nil; i++ {
+			at := start.Add(time.Duration(s)*time.Second + time.Duration(i)*time.Second/time.Duration(n))
+			sleepContext(ctx, time.Until(at))
+			if ctx.Err() != nil {
+				break
+			}
+
+			wg.Add(1)
+			go func(s, i int) {
+				defer wg.Done()
+				inv, err := w.invokeOnce(ctx, meta.Name, payload)
+				inv.Index, inv.Err, inv.Category = i, err, ClassifyError(err)
+
+				mu.Lock()
+				buckets[s].Invocations = append(buckets[s].Invocations, *inv)
+				mu.Unlock()
+				samples.write(*inv)
+			}(s, index)
+			index++
+		}
+	}
+	// let the last second run out before waiting for the stragglers
+	sleepContext(ctx, time.Until(start.Add(duration)))
+	wall := time.Since(start)
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	result := &LoadResult{Meta: *meta, Buckets: buckets}
+	var all []Invocation
+	for s := range buckets {
+		invocations := buckets[s].Invocations
+		sort.Slice(invocations, func(i, j int) bool { return invocations[i].Index < invocations[j].Index })
+		buckets[s].Stats = latencyStats(buckets[s].Invocations, time.Second)
+		all = append(all, buckets[s].Invocations...)
+	}
+	result.Stats = latencyStats(all, wall)
+	return result, nil
+}
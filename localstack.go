@@ -0,0 +1,126 @@
+package sls
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const localStackPlugin = "serverless-localstack"
+
+// localStackConfigName is the config generated for deploying the wrapper's suffix to LocalStack
+func (w *Wrapper) localStackConfigName() string {
+	return "serverless-" + w.suffix + "-localstack.yml"
+}
+
+// localStackEnv points the framework and the aws cli at the endpoint, LocalStack accepts any credentials so
+// placeholders are used when none are set
+func (w *Wrapper) localStackEnv() []string {
+	env := []string{"AWS_ENDPOINT_URL=" + w.LocalStackEndpoint}
+	if u, err := url.Parse(w.LocalStackEndpoint); err == nil {
+		env = append(env, "LOCALSTACK_HOSTNAME="+u.Hostname())
+		if u.Port() != "" {
+			env = append(env, "EDGE_PORT="+u.Port())
+		}
+	}
+	if os.Getenv("AWS_ACCESS_KEY_ID") == "" {
+		env = append(env, "AWS_ACCESS_KEY_ID=test", "AWS_SECRET_ACCESS_KEY=test")
+	}
+	return env
+}
+
+// cmdEnv adds the LocalStack variables to the framework's and the aws cli's environment
+func (w *Wrapper) cmdEnv(command string, env []string) []string {
+	if w.LocalStackEndpoint == "" || (command != "sls" && command != "aws") {
+		return env
+	}
+	return append(append([]string{}, env...), w.localStackEnv()...)
+}
+
+// withLocalStackPlugin adds the plugin to the config's plugins, which are listed directly or under modules
+func withLocalStackPlugin(config yaml.MapSlice) yaml.MapSlice {
+	var plugins []interface{}
+	var modules yaml.MapSlice
+	if i := mapSliceIndex(config, "plugins"); i >= 0 {
+		switch v := config[i].Value.(type) {
+		case []interface{}:
+			plugins = v
+		case yaml.MapSlice:
+			modules = v
+			if j := mapSliceIndex(v, "modules"); j >= 0 {
+				plugins, _ = v[j].Value.([]interface{})
+			}
+		}
+	}
+	for _, p := range plugins {
+		if p == localStackPlugin {
+			return config
+		}
+	}
+	plugins = append(append([]interface{}{}, plugins...), localStackPlugin)
+	if modules != nil {
+		return setMapSliceItem(config, "plugins", setMapSliceItem(append(yaml.MapSlice{}, modules...), "modules", plugins))
+	}
+	return setMapSliceItem(config, "plugins", plugins)
+}
+
+// WithLocalStack returns a wrapper deploying the same suffix to a LocalStack endpoint such as http://localhost:4566,
+// through a generated config that enables serverless-localstack for the wrapper's stage
+func (w *Wrapper) WithLocalStack(endpoint string) (*Wrapper, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid localstack endpoint %s", endpoint)
+	}
+	if !fileExists(filepath.Join(w.yamlDirPath, "node_modules", localStackPlugin)) {
+		return nil, fmt.Errorf("plugin %s isn't installed in %s", localStackPlugin, w.yamlDirPath)
+	}
+
+	yamlData, err := ioutil.ReadFile(w.configPath())
+	if err != nil {
+		return nil, err
+	}
+	var config yaml.MapSlice
+	err = yaml.Unmarshal(yamlData, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	localstack := yaml.MapSlice{
+		{Key: "stages", Value: []string{w.resolvedStage()}},
+		{Key: "host", Value: u.Scheme + "://" + u.Hostname()},
+		{Key: "autostart", Value: false},
+	}
+	if port, err := strconv.Atoi(u.Port()); err == nil {
+		localstack = append(localstack, yaml.MapItem{Key: "edgePort", Value: port})
+	}
+	var custom yaml.MapSlice
+	if i := mapSliceIndex(config, "custom"); i >= 0 {
+		custom, _ = config[i].Value.(yaml.MapSlice)
+	}
+	custom = setMapSliceItem(append(yaml.MapSlice{}, custom...), "localstack", localstack)
+	config = setMapSliceItem(withLocalStackPlugin(config), "custom", custom)
+
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	name := w.localStackConfigName()
+	err = ioutil.WriteFile(filepath.Join(w.yamlDirPath, name), out, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := *w
+	clone.configName = name
+	other, err := clone.WithSuffix(w.suffix)
+	if err != nil {
+		os.Remove(filepath.Join(w.yamlDirPath, name))
+		return nil, err
+	}
+	other.LocalStackEndpoint = endpoint
+	return other, nil
+}
@@ -0,0 +1,140 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var lockPollInterval = time.Second
+
+// Locker serializes deploys and removals of the same stack across processes
+type Locker interface {
+	Lock(ctx context.Context, key string) (unlock func() error, err error)
+}
+
+var unsafeKeyRe = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+type FileLocker struct {
+	Dir string
+}
+
+func (l *FileLocker) Lock(ctx context.Context, key string) (func() error, error) {
+	err := os.MkdirAll(l.Dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(l.Dir, unsafeKeyRe.ReplaceAllString(key, "_")+".lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if locked {
+			return func() error {
+				unlockFile(f)
+				return f.Close()
+			}, nil
+		}
+		if ctx.Err() != nil {
+			f.Close()
+			return nil, fmt.Errorf("waiting for lock %s: %v", key, ctx.Err())
+		}
+		sleepContext(ctx, lockPollInterval)
+	}
+}
+
+// DynamoDBLocker locks through conditional writes to a table whose hash key is LockID,
+// locks expire after TTL so a crashed holder doesn't block the stack forever
+type DynamoDBLocker struct {
+	Table  string
+	Region string
+	TTL    time.Duration
+}
+
+func lockOwner() string {
+	host, _ := os.Hostname()
+	return host + "-" + strconv.Itoa(os.Getpid())
+}
+
+func (l *DynamoDBLocker) Lock(ctx context.Context, key string) (func() error, error) {
+	ttl := l.TTL
+	if ttl == 0 {
+		ttl = time.Hour
+	}
+	owner := lockOwner() + "-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	for {
+		now := time.Now().Unix()
+		item := fmt.Sprintf(`{"LockID":{"S":%q},"Owner":{"S":%q},"Expires":{"N":"%d"}}`, key, owner, now+int64(ttl.Seconds()))
+		values := fmt.Sprintf(`{":now":{"N":"%d"}}`, now)
+		_, err := runAws(ctx, l.Region, "dynamodb", "put-item",
+			"--table-name", l.Table,
+			"--item", item,
+			"--condition-expression", "attribute_not_exists(LockID) OR Expires < :now",
+			"--expression-attribute-values", values)
+		if err == nil {
+			return func() error { return l.unlock(key, owner) }, nil
+		}
+		if awsErr, ok := err.(*awsError); !ok || !strings.Contains(awsErr.stderr, "ConditionalCheckFailed") {
+			return nil, err
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("waiting for lock %s: %v", key, ctx.Err())
+		}
+		sleepContext(ctx, lockPollInterval)
+	}
+}
+
+func (l *DynamoDBLocker) unlock(key string, owner string) error {
+	_, err := runAws(context.Background(), l.Region, "dynamodb", "delete-item",
+		"--table-name", l.Table,
+		"--key", fmt.Sprintf(`{"LockID":{"S":%q}}`, key),
+		"--condition-expression", "Owner = :owner",
+		"--expression-attribute-values", fmt.Sprintf(`{":owner":{"S":%q}}`, owner))
+	return err
+}
+
+func (w *Wrapper) lockStack(ctx context.Context) (func() error, error) {
+	if w.Locker == nil {
+		return func() error { return nil }, nil
+	}
+	// stack names repeat across regions
+	return w.Locker.Lock(ctx, w.StackName()+"@"+w.Region())
+}
+
+var dirLocks = struct {
+	sync.Mutex
+	locks map[string]*sync.Mutex
+}{locks: make(map[string]*sync.Mutex)}
+
+// lockDir serializes builds and the sls runs that package within this process on the same project dir, since both
+// the builders and the framework's .serverless output share it between suffixes and regions
+func (w *Wrapper) lockDir() func() {
+	dir, err := filepath.Abs(w.yamlDirPath)
+	if err != nil {
+		dir = w.yamlDirPath
+	}
+
+	dirLocks.Lock()
+	l, ok := dirLocks.locks[dir]
+	if !ok {
+		l = &sync.Mutex{}
+		dirLocks.locks[dir] = l
+	}
+	dirLocks.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
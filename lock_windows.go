@@ -0,0 +1,24 @@
+//go:build windows
+// +build windows
+
+package sls
+
+import (
+	"os"
+)
+
+// windows has no flock, a marker file next to the lock file stands in for it
+func tryLockFile(f *os.File) (bool, error) {
+	marker, err := os.OpenFile(f.Name()+".held", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, marker.Close()
+}
+
+func unlockFile(f *os.File) {
+	os.Remove(f.Name() + ".held")
+}
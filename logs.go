@@ -0,0 +1,246 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logTimeLayout is how sls logs formats the time of the lines the runtimes log
+const logTimeLayout = "2006-01-02 15:04:05.000 (-07:00)"
+
+var (
+	logRequestIDRe = regexp.MustCompile(`^(?:START|END|REPORT) RequestId: (\S+)`)
+	logLevels      = map[string]bool{"TRACE": true, "DEBUG": true, "INFO": true, "WARN": true, "ERROR": true, "FATAL": true}
+)
+
+type LogEntry struct {
+	// Timestamp is zero for the START, END and REPORT lines and for lines logged without the runtime's prefix
+	Timestamp time.Time
+	RequestID string
+	Level     string
+	Message   string
+}
+
+type LogsOptions struct {
+	// StartTime is passed to sls logs, e.g. "30m" or "2021-01-01T10:00:00"
+	StartTime string
+	Filter    string
+}
+
+// logParser groups the lines of sls logs into entries, lines without the runtime's prefix continue the previous
+// entry, or after a START line are entries of the request that started
+type logParser struct {
+	requestID string
+	entry     *LogEntry
+}
+
+// line returns the entries the line completes, START, END and REPORT lines complete right away
+func (p *logParser) line(line string) []LogEntry {
+	line = strings.TrimRight(ansiRe.ReplaceAllString(line, ""), "\r")
+	if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "Serverless: ") {
+		return nil
+	}
+
+	var next *LogEntry
+	marker := false
+	if m := logRequestIDRe.FindStringSubmatch(line); m != nil {
+		next = &LogEntry{RequestID: m[1], Message: line}
+		p.requestID = m[1]
+		marker = true
+	} else if fields := strings.SplitN(line, "\t", 3); len(fields) == 3 {
+		if ts, err := time.Parse(logTimeLayout, fields[0]); err == nil {
+			next = &LogEntry{Timestamp: ts, RequestID: fields[1], Message: fields[2]}
+			if parts := strings.SplitN(fields[2], "\t", 2); len(parts) == 2 && logLevels[parts[0]] {
+				next.Level, next.Message = parts[0], parts[1]
+			}
+		}
+	}
+	if next == nil {
+		if p.entry != nil && !p.entry.Timestamp.IsZero() {
+			p.entry.Message += "\n" + line
+			return nil
+		}
+		next = &LogEntry{RequestID: p.requestID, Message: line}
+	}
+
+	var done []LogEntry
+	if p.entry != nil {
+		done = append(done, *p.entry)
+	}
+	p.entry = next
+	if marker {
+		done = append(done, *next)
+		p.entry = nil
+	}
+	return done
+}
+
+func (p *logParser) flush() []LogEntry {
+	if p.entry == nil {
+		return nil
+	}
+	entry := *p.entry
+	p.entry = nil
+	return []LogEntry{entry}
+}
+
+func parseLogs(out string) []LogEntry {
+	p := &logParser{}
+	var entries []LogEntry
+	for _, line := range strings.Split(out, "\n") {
+		entries = append(entries, p.line(line)...)
+	}
+	return append(entries, p.flush()...)
+}
+
+// Logs runs sls logs for the function and parses its output, a function that never ran has no entries
+func (w *Wrapper) Logs(ctx context.Context, key string, opts LogsOptions) ([]LogEntry, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	args := []string{"logs", "-f", key}
+	if opts.StartTime != "" {
+		args = append(args, "--startTime", opts.StartTime)
+	}
+	if opts.Filter != "" {
+		args = append(args, "--filter", opts.Filter)
+	}
+	out, err := w.execSlsCmd(ctx, w.yamlDirPath, args...)
+	if err != nil {
+		if strings.Contains(out, "No existing streams") || strings.Contains(err.Error(), "No existing streams") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parseLogs(out), nil
+}
+
+// logWriter parses the lines written to it into entries for fn
+type logWriter struct {
+	mu     sync.Mutex
+	line   string
+	parser logParser
+	fn     func(LogEntry)
+}
+
+func (lw *logWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	lw.line += string(p)
+	lines := strings.Split(lw.line, "\n")
+	lw.line = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		for _, e := range lw.parser.line(line) {
+			lw.fn(e)
+		}
+	}
+	return len(p), nil
+}
+
+func (lw *logWriter) flush() {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	entries := lw.parser.line(lw.line)
+	lw.line = ""
+	for _, e := range append(entries, lw.parser.flush()...) {
+		lw.fn(e)
+	}
+}
+
+// TailLogs runs sls logs --tail for the function, passing each entry to fn until ctx is done, an entry is passed once
+// the next line shows it's complete
+func (w *Wrapper) TailLogs(ctx context.Context, key string, fn func(LogEntry)) error {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	lw := &logWriter{fn: fn}
+	_, err := w.execCmdOutput(ctx, []string{}, w.yamlDirPath, lw, os.Stderr, "sls", w.slsArgs("logs", "-f", key, "--tail")...)
+	lw.flush()
+	return err
+}
+
+// requestIDFromLogs finds the request id in a log tail, which starts with the START line unless it was cut
+func requestIDFromLogs(logs string) string {
+	for _, line := range strings.Split(logs, "\n") {
+		if m := logRequestIDRe.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// logEntry converts a raw CloudWatch event, whose runtime prefix is "<time>\t<request id>\t<level>\t"
+func logEntry(e logEvent, requestID string) LogEntry {
+	entry := LogEntry{
+		Timestamp: time.Unix(0, e.Timestamp*int64(time.Millisecond)).UTC(),
+		RequestID: requestID,
+		Message:   strings.TrimRight(e.Message, "\n"),
+	}
+	if fields := strings.SplitN(entry.Message, "\t", 4); len(fields) >= 3 && fields[1] == requestID {
+		entry.Message = strings.Join(fields[2:], "\t")
+		if len(fields) == 4 && logLevels[fields[2]] {
+			entry.Level, entry.Message = fields[2], fields[3]
+		}
+	}
+	return entry
+}
+
+// RequestLogs returns the log entries of a single invocation of the function, pattern optionally narrows them down
+// with a CloudWatch filter pattern
+func (w *Wrapper) RequestLogs(ctx context.Context, key string, requestID string, since time.Time, pattern string) ([]LogEntry, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	name := w.functionName(key)
+
+	// the START, END and REPORT lines locate the invocation's stream and time span
+	markers, err := w.filterLogEvents(ctx, name, since, "--filter-pattern", `"RequestId: `+requestID+`"`)
+	if err != nil {
+		return nil, err
+	}
+	var start, report *logEvent
+	for i := range markers {
+		switch {
+		case strings.HasPrefix(markers[i].Message, "START ") && start == nil:
+			start = &markers[i]
+		case strings.HasPrefix(markers[i].Message, "REPORT ") && start != nil && markers[i].LogStreamName == start.LogStreamName:
+			report = &markers[i]
+		}
+	}
+	if start == nil {
+		return nil, fmt.Errorf("no logs of request %s of %s", requestID, name)
+	}
+
+	args := []string{"--log-stream-names", start.LogStreamName}
+	if report != nil {
+		args = append(args, "--end-time", strconv.FormatInt(report.Timestamp+1, 10))
+	}
+	if pattern != "" {
+		args = append(args, "--filter-pattern", pattern)
+	}
+	events, err := w.filterLogEvents(ctx, name, time.Unix(0, start.Timestamp*int64(time.Millisecond)), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []LogEntry
+	for _, e := range events {
+		// the stream's previous and next invocations may have logged in the same millisecond as the span's ends
+		if m := logRequestIDRe.FindStringSubmatch(e.Message); m != nil && m[1] != requestID {
+			if len(entries) > 0 {
+				break
+			}
+			continue
+		}
+		entries = append(entries, logEntry(e, requestID))
+	}
+	return entries, nil
+}
@@ -0,0 +1,209 @@
+package sls
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const redacted = "<redacted>"
+
+// deploymentBucketOutput is the stack output naming the deployment bucket, the framework sets it for configured
+// buckets too
+const deploymentBucketOutput = "ServerlessDeploymentBucketName"
+
+// manifestOpts are the options written to the manifest as they are, the values of other options may be secrets
+var manifestOpts = map[string]bool{"stage": true, "region": true, "aws-profile": true}
+
+var secretKeys = []string{"password", "secret", "token"}
+
+// ConfigSnapshot is the deployed config, Sha256 is of the file as it was while Yaml has its secrets redacted
+type ConfigSnapshot struct {
+	Sha256 string `json:"sha256"`
+	Yaml   string `json:"yaml"`
+}
+
+type Manifest struct {
+	Service  string `json:"service"`
+	Provider string `json:"provider"`
+	Stage    string `json:"stage"`
+	Suffix   string `json:"suffix"`
+	// Opts holds the values of the stage, region and profile options, other options are listed redacted
+	Opts      map[string]string `json:"opts,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Config    ConfigSnapshot    `json:"config"`
+	Artifacts []BuildResult     `json:"artifacts"`
+}
+
+func (w *Wrapper) manifestPath() string {
+	return filepath.Join(w.stateDir(), "manifest-"+w.instanceKey()+".json")
+}
+
+func (w *Wrapper) manifestOpts() map[string]string {
+	opts := make(map[string]string, len(w.Opts))
+	for opt, val := range w.Opts {
+		if !manifestOpts[opt] {
+			val = redacted
+		}
+		opts[opt] = val
+	}
+	return opts
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range secretKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func isVariableRef(v interface{}) bool {
+	s, ok := v.(string)
+	return ok && strings.HasPrefix(s, "${") && strings.HasSuffix(s, "}")
+}
+
+// redactSecrets replaces the values of environment variables and of keys named like passwords, secrets or tokens,
+// values that only reference a variable are kept since they don't hold the secret
+func redactSecrets(v interface{}, secret bool) interface{} {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		for i, item := range v {
+			key, _ := item.Key.(string)
+			v[i].Value = redactSecrets(item.Value, secret || key == "environment" || isSecretKey(key))
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = redactSecrets(v[i], secret)
+		}
+		return v
+	}
+	if !secret || v == nil || isVariableRef(v) {
+		return v
+	}
+	return redacted
+}
+
+func redactConfig(data []byte) (string, error) {
+	var config yaml.MapSlice
+	err := yaml.Unmarshal(data, &config)
+	if err != nil {
+		return "", err
+	}
+	out, err := yaml.Marshal(redactSecrets(config, false))
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// writeManifest records the deployment in the state dir and, on aws, next to the framework's deployments in the
+// deployment bucket, failing to upload it doesn't fail the deploy that already succeeded
+func (w *Wrapper) writeManifest(ctx context.Context, results []BuildResult) error {
+	yamlData, err := ioutil.ReadFile(w.configPath())
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(yamlData)
+	config, err := redactConfig(yamlData)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %v", w.configPath(), err)
+	}
+
+	manifest := Manifest{
+		Service:   w.StackId(),
+		Provider:  w.provider,
+		Stage:     w.Stage(),
+		Suffix:    w.suffix,
+		Opts:      w.manifestOpts(),
+		CreatedAt: time.Now().UTC(),
+		Config:    ConfigSnapshot{Sha256: hex.EncodeToString(sum[:]), Yaml: config},
+		Artifacts: results,
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(w.stateDir(), 0755)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(w.manifestPath(), data, 0644)
+	if err != nil {
+		return err
+	}
+	if w.provider != "aws" {
+		return nil
+	}
+	err = w.uploadManifest(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+	return nil
+}
+
+// manifestKey is beside the framework's timestamped deployment dirs of the stack, so removing those removes it too
+func (w *Wrapper) manifestKey() string {
+	stage := w.resolvedStage()
+	service := strings.TrimSuffix(w.StackName(), "-"+stage)
+	return deploymentPrefix + service + "/" + stage + "/manifest.json"
+}
+
+func (w *Wrapper) uploadManifest(ctx context.Context) error {
+	outputs, err := w.Outputs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to upload the manifest of stack %s: %v", w.StackName(), err)
+	}
+	bucket, err := outputs.String(deploymentBucketOutput)
+	if err != nil {
+		return fmt.Errorf("failed to upload the manifest of stack %s: %v", w.StackName(), err)
+	}
+	err = w.awsCmd(ctx, nil, "s3", "cp", w.manifestPath(), "s3://"+bucket+"/"+w.manifestKey())
+	if err != nil {
+		return fmt.Errorf("failed to upload the manifest of stack %s: %v", w.StackName(), err)
+	}
+	return nil
+}
+
+func (w *Wrapper) LoadManifest() (*Manifest, error) {
+	data, err := ioutil.ReadFile(w.manifestPath())
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	err = json.Unmarshal(data, manifest)
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Verify checks the artifacts on disk still match the checksums recorded at deploy time
+func (m *Manifest) Verify() error {
+	for _, a := range m.Artifacts {
+		if a.Artifact == "" {
+			continue
+		}
+		_, sum, err := fileChecksum(a.Artifact)
+		if err != nil {
+			return err
+		}
+		if sum != a.Sha256 {
+			return fmt.Errorf("artifact %s changed since it was deployed: expected sha256 %s, found %s", a.Artifact, a.Sha256, sum)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const metricsTimeLayout = "2006-01-02T15:04:05Z"
+
+type FunctionMetrics struct {
+	Invocations     int64
+	Errors          int64
+	Throttles       int64
+	AverageDuration time.Duration
+}
+
+func parseMetrics(out string) (*FunctionMetrics, error) {
+	m := &FunctionMetrics{}
+	for _, line := range strings.Split(ansiRe.ReplaceAllString(out, ""), "\n") {
+		key, value, ok := splitKeyValue(line)
+		if !ok || value == "" {
+			continue
+		}
+		var err error
+		switch key {
+		case "Invocations":
+			m.Invocations, err = strconv.ParseInt(value, 10, 64)
+		case "Errors":
+			m.Errors, err = strconv.ParseInt(value, 10, 64)
+		case "Throttles":
+			m.Throttles, err = strconv.ParseInt(value, 10, 64)
+		case "Duration (avg.)":
+			m.AverageDuration, err = time.ParseDuration(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the %s metric: %v", key, err)
+		}
+	}
+	return m, nil
+}
+
+// Metrics runs sls metrics for the function over the window up to now
+func (w *Wrapper) Metrics(ctx context.Context, key string, window time.Duration) (*FunctionMetrics, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	end := time.Now().UTC()
+	out, err := w.execSlsCmd(ctx, w.yamlDirPath, "metrics", "-f", key,
+		"--startTime", end.Add(-window).Format(metricsTimeLayout), "--endTime", end.Format(metricsTimeLayout))
+	if err != nil {
+		return nil, err
+	}
+	return parseMetrics(out)
+}
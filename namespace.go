@@ -0,0 +1,206 @@
+package sls
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const suffixVar = "${opt:suffix}"
+
+var (
+	nonAlnumRe = regexp.MustCompile(`[^A-Za-z0-9]`)
+	// namedProperties are the resource properties that give resources account wide names
+	namedProperties = []string{"BucketName", "TableName", "QueueName", "TopicName", "RoleName", "FunctionName", "StreamName"}
+)
+
+// Namespace is a suffix handed out by Namespaces, tracked until it's released
+type Namespace struct {
+	Suffix    string    `json:"suffix"`
+	Owner     string    `json:"owner"`
+	Pid       int       `json:"pid"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Namespaces hands out unique suffixes to concurrent test runs, tracking them as files in Dir so every process on
+// the machine, or every job sharing Dir, sees the others'
+type Namespaces struct {
+	Dir string
+}
+
+func (n *Namespaces) path(suffix string) string {
+	return filepath.Join(n.Dir, suffix+".json")
+}
+
+// Acquire reserves a new suffix, owner is a short alphanumeric name of what uses it, e.g. the test package
+func (n *Namespaces) Acquire(owner string) (*Namespace, error) {
+	err := os.MkdirAll(n.Dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.ToLower(nonAlnumRe.ReplaceAllString(owner, ""))
+	if len(prefix) > 8 {
+		prefix = prefix[:8]
+	}
+
+	for {
+		random := make([]byte, 4)
+		_, err = rand.Read(random)
+		if err != nil {
+			return nil, err
+		}
+		ns := &Namespace{Suffix: prefix + hex.EncodeToString(random), Owner: owner, Pid: os.Getpid(), CreatedAt: time.Now().UTC()}
+		// O_EXCL makes a suffix taken by a concurrent Acquire fail here rather than be handed out twice
+		f, err := os.OpenFile(n.path(ns.Suffix), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = json.NewEncoder(f).Encode(ns)
+		closeErr := f.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(n.path(ns.Suffix))
+			return nil, err
+		}
+		return ns, nil
+	}
+}
+
+func (n *Namespaces) Release(suffix string) error {
+	err := os.Remove(n.path(suffix))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// List returns the suffixes in use, oldest first
+func (n *Namespaces) List() ([]Namespace, error) {
+	files, err := filepath.Glob(filepath.Join(n.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var namespaces []Namespace
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var ns Namespace
+		if json.Unmarshal(data, &ns) == nil {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].CreatedAt.Before(namespaces[j].CreatedAt) })
+	return namespaces, nil
+}
+
+// New acquires a suffix and returns a wrapper for it, after checking the config names everything with the suffix
+func (n *Namespaces) New(provider string, yamlDirPath string, owner string) (*Wrapper, error) {
+	err := ValidateSuffixedNames(yamlDirPath)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := n.Acquire(owner)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewWithSuffix(provider, yamlDirPath, ns.Suffix)
+	if err != nil {
+		n.Release(ns.Suffix)
+		return nil, err
+	}
+	return w, nil
+}
+
+// suffixed reports whether a name from the config varies with the suffix, directly or through the service name
+func suffixed(name string) bool {
+	return strings.Contains(name, suffixVar) || strings.Contains(name, "${self:service}") ||
+		strings.Contains(name, "AWS::StackName")
+}
+
+// ValidateSuffixedNames checks that every account wide name in the dir's config includes the suffix, so stacks of
+// different suffixes can't collide: the service, explicit function and layer names, the deployment bucket and the
+// names of buckets, tables, queues, topics, roles and streams among the resources
+func ValidateSuffixedNames(yamlDirPath string) error {
+	data, err := ioutil.ReadFile(filepath.Join(yamlDirPath, YamlName))
+	if err != nil {
+		return err
+	}
+	var config map[interface{}]interface{}
+	err = yaml.Unmarshal(data, &config)
+	if err != nil {
+		return err
+	}
+
+	var unsuffixed []string
+	check := func(what string, v interface{}) {
+		// names given as intrinsic functions are derived from the stack
+		if name, ok := v.(string); ok && name != "" && !suffixed(name) {
+			unsuffixed = append(unsuffixed, fmt.Sprintf("%s %q", what, name))
+		}
+	}
+	field := func(v interface{}, key string) interface{} {
+		m, _ := v.(map[interface{}]interface{})
+		return m[key]
+	}
+	sortedMap := func(v interface{}) ([]string, map[interface{}]interface{}) {
+		m, _ := v.(map[interface{}]interface{})
+		var keys []string
+		for k := range m {
+			keys = append(keys, fmt.Sprint(k))
+		}
+		sort.Strings(keys)
+		return keys, m
+	}
+
+	service := config["service"]
+	if m, ok := service.(map[interface{}]interface{}); ok {
+		service = m["name"]
+	}
+	if name, _ := service.(string); !strings.Contains(name, suffixVar) {
+		unsuffixed = append(unsuffixed, fmt.Sprintf("service %q", name))
+	}
+
+	bucket := field(config["provider"], "deploymentBucket")
+	if _, ok := bucket.(map[interface{}]interface{}); ok {
+		bucket = field(bucket, "name")
+	}
+	check("deployment bucket", bucket)
+
+	keys, functions := sortedMap(config["functions"])
+	for _, k := range keys {
+		check("function "+k, field(functions[k], "name"))
+	}
+	keys, layers := sortedMap(config["layers"])
+	for _, k := range keys {
+		check("layer "+k, field(layers[k], "name"))
+	}
+	keys, resources := sortedMap(field(config["resources"], "Resources"))
+	for _, k := range keys {
+		props := field(resources[k], "Properties")
+		for _, p := range namedProperties {
+			check(fmt.Sprintf("resource %s %s", k, p), field(props, p))
+		}
+	}
+
+	if len(unsuffixed) > 0 {
+		return fmt.Errorf("names in %s don't include %s: %s", YamlName, suffixVar, strings.Join(unsuffixed, ", "))
+	}
+	return nil
+}
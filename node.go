@@ -0,0 +1,214 @@
+package sls
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var nodeSourceExts = []string{".js", ".ts", ".mjs", ".cjs"}
+
+type nodeFunction struct {
+	key      string
+	runtime  string
+	entry    string
+	zipName  string
+	bundleJS string
+}
+
+func (w *Wrapper) functionRuntime(f FunctionMeta) string {
+	if f.Runtime != "" {
+		return f.Runtime
+	}
+	return w.stack.Provider.Runtime
+}
+
+func handlerFile(handler string) string {
+	i := strings.LastIndex(handler, ".")
+	if i < 0 {
+		return handler
+	}
+	return handler[:i]
+}
+
+func (w *Wrapper) nodeFunctions(platform string, nodePath string) []nodeFunction {
+	var functions []nodeFunction
+	for key, f := range w.stack.Functions {
+		runtime := w.functionRuntime(f)
+		if runtimePlatform(runtime) != "nodejs" || !strings.HasPrefix(f.Handler, platform+"/") {
+			continue
+		}
+		file := handlerFile(f.Handler)
+		entry, ok := w.nodeEntry(nodePath, file)
+		if !ok {
+			continue
+		}
+		functions = append(functions, nodeFunction{
+			key:      key,
+			runtime:  runtime,
+			entry:    entry,
+			zipName:  file + ".js",
+			bundleJS: w.outputPath(platform, "dist", key, path.Base(file)+".js"),
+		})
+	}
+	sort.Slice(functions, func(i, j int) bool { return functions[i].key < functions[j].key })
+	return functions
+}
+
+// nodeEntry finds the source of a handler, handlers pointing into the typescript outDir are mapped back to rootDir
+func (w *Wrapper) nodeEntry(nodePath string, file string) (string, bool) {
+	candidates := []string{filepath.Join(w.yamlDirPath, filepath.FromSlash(file))}
+
+	if ts, ok := readTSConfig(nodePath); ok && ts.CompilerOptions.OutDir != "" {
+		rel, err := filepath.Rel(nodePath, filepath.Join(w.yamlDirPath, filepath.FromSlash(file)))
+		outDir := filepath.Clean(ts.CompilerOptions.OutDir)
+		if err == nil && strings.HasPrefix(rel, outDir+string(filepath.Separator)) {
+			src := filepath.Join(nodePath, ts.CompilerOptions.RootDir, strings.TrimPrefix(rel, outDir+string(filepath.Separator)))
+			candidates = append(candidates, src)
+		}
+	}
+
+	for _, candidate := range candidates {
+		for _, ext := range nodeSourceExts {
+			if fileExists(candidate + ext) {
+				return candidate + ext, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (w *Wrapper) buildNode(log *buildLog, platform string, nodePath string) error {
+	if fileExists(filepath.Join(nodePath, "package.json")) {
+		args := []string{"install"}
+		// bundlers and tsc are usually dev dependencies
+		if !w.BundleNode && !fileExists(filepath.Join(nodePath, tsConfigName)) {
+			args = append(args, "--production")
+		}
+		_, err := log.execCmd(w, []string{}, nodePath, "npm", args...)
+		if err != nil {
+			return err
+		}
+	}
+	if !w.BundleNode {
+		return w.compileTypeScript(log, platform, nodePath)
+	}
+	// esbuild and webpack transpile typescript entries on their own
+	return w.bundleNode(log, platform, nodePath)
+}
+
+func (w *Wrapper) bundleNode(log *buildLog, platform string, nodePath string) error {
+	for _, f := range w.nodeFunctions(platform, nodePath) {
+		err := w.bundleNodeFunction(log, nodePath, f)
+		if err != nil {
+			return err
+		}
+		files := []artifactFile{{src: f.bundleJS, name: f.zipName}}
+		artifact := w.outputPath(platform, "dist", f.key+".zip")
+		err = zipArtifact(artifact, files, w.artifactModTime())
+		if err != nil {
+			return err
+		}
+		log.addArtifact(artifact, f.key)
+	}
+	return nil
+}
+
+func (w *Wrapper) bundleNodeFunction(log *buildLog, nodePath string, f nodeFunction) error {
+	if esbuild, ok := nodeTool(nodePath, "esbuild"); ok {
+		_, err := log.execCmd(w, []string{}, nodePath, esbuild,
+			f.entry,
+			"--bundle",
+			"--minify",
+			"--platform=node",
+			"--target="+nodeTarget(f.runtime),
+			"--outfile="+f.bundleJS)
+		return err
+	}
+
+	if webpack, ok := nodeTool(nodePath, "webpack"); ok {
+		_, err := log.execCmd(w, []string{}, nodePath, webpack,
+			"--mode", "production",
+			"--target", "node",
+			"--entry", f.entry,
+			"--output-path", filepath.Dir(f.bundleJS),
+			"--output-filename", filepath.Base(f.bundleJS),
+			"--output-library-type", "commonjs2")
+		return err
+	}
+
+	return errors.New("bundling requires esbuild or webpack, neither was found in node_modules or PATH")
+}
+
+func nodeTool(nodePath string, name string) (string, bool) {
+	local := filepath.Join(nodePath, "node_modules", ".bin", name)
+	if fileExists(local) {
+		return local, true
+	}
+	global, err := exec.LookPath(name)
+	if err != nil {
+		return "", false
+	}
+	return global, true
+}
+
+func nodeTarget(runtime string) string {
+	version := strings.TrimSuffix(strings.TrimPrefix(runtime, "nodejs"), ".x")
+	if version == "" {
+		return "node12"
+	}
+	return "node" + version
+}
+
+const tsConfigName = "tsconfig.json"
+
+type tsConfig struct {
+	CompilerOptions struct {
+		OutDir  string `json:"outDir"`
+		RootDir string `json:"rootDir"`
+	} `json:"compilerOptions"`
+}
+
+func readTSConfig(nodePath string) (*tsConfig, bool) {
+	data, err := ioutil.ReadFile(filepath.Join(nodePath, tsConfigName))
+	if err != nil {
+		return nil, false
+	}
+	ts := &tsConfig{}
+	// tsconfig allows comments, in which case only the defaults are used
+	json.Unmarshal(data, ts)
+	return ts, true
+}
+
+func (w *Wrapper) compileTypeScript(log *buildLog, platform string, nodePath string) error {
+	ts, ok := readTSConfig(nodePath)
+	if !ok {
+		return nil
+	}
+
+	tsc, ok := nodeTool(nodePath, "tsc")
+	if !ok {
+		return errors.New(tsConfigName + " found but tsc is not installed")
+	}
+	_, err := log.execCmd(w, []string{}, nodePath, tsc, "-p", tsConfigName)
+	if err != nil {
+		return err
+	}
+
+	for key, f := range w.stack.Functions {
+		if runtimePlatform(w.functionRuntime(f)) != "nodejs" || !strings.HasPrefix(f.Handler, platform+"/") {
+			continue
+		}
+		compiled := filepath.Join(w.yamlDirPath, filepath.FromSlash(handlerFile(f.Handler))+".js")
+		if !fileExists(compiled) {
+			return fmt.Errorf("function %s: handler %s has no compiled output at %s (tsconfig outDir is %q)", key, f.Handler, compiled, ts.CompilerOptions.OutDir)
+		}
+	}
+	return nil
+}
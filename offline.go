@@ -0,0 +1,173 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const offlineStartTimeout = 2 * time.Minute
+
+// offlineReadyRe matches the banner of serverless-offline v6 and later and the listening line of older versions
+var offlineReadyRe = regexp.MustCompile(`(?:Server ready|listening on):? (https?://[^\s]+)`)
+
+// lineWatcher calls onLine with every complete line written to it, without ansi codes
+type lineWatcher struct {
+	mu     sync.Mutex
+	line   string
+	onLine func(line string)
+}
+
+func (lw *lineWatcher) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	lw.line += string(p)
+	lines := strings.Split(lw.line, "\n")
+	lw.line = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		lw.onLine(strings.TrimSpace(ansiRe.ReplaceAllString(line, "")))
+	}
+	return len(p), nil
+}
+
+// background is an sls command running until it's stopped
+type background struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// startBackground runs sls in the background, returning once onLine reports the ready line, the command runs until
+// stop or until ctx is done
+func (w *Wrapper) startBackground(ctx context.Context, timeout time.Duration, onLine func(line string) bool, slsCmd ...string) (*background, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	b := &background{cancel: cancel, done: make(chan struct{})}
+	ready := make(chan struct{})
+	var once sync.Once
+	watcher := &lineWatcher{onLine: func(line string) {
+		if onLine(line) {
+			once.Do(func() { close(ready) })
+		}
+	}}
+
+	args := w.slsArgs(slsCmd...)
+	go func() {
+		defer close(b.done)
+		stdout := io.MultiWriter(os.Stdout, watcher)
+		stderr := io.MultiWriter(os.Stderr, watcher)
+		_, b.err = w.execCmdIO(ctx, []string{}, w.yamlDirPath, nil, stdout, stderr, "sls", args...)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-ready:
+		return b, nil
+	case <-b.done:
+		cancel()
+		return nil, fmt.Errorf("sls %s exited before it was ready: %v", slsCmd[0], b.err)
+	case <-timer.C:
+		cancel()
+		<-b.done
+		return nil, fmt.Errorf("sls %s wasn't ready after %v", slsCmd[0], timeout)
+	case <-ctx.Done():
+		<-b.done
+		return nil, ctx.Err()
+	}
+}
+
+// wait blocks until the command exits, returning its error
+func (b *background) wait() error {
+	<-b.done
+	return b.err
+}
+
+// stop interrupts the command and waits for it to exit
+func (b *background) stop() error {
+	b.cancel()
+	<-b.done
+	if b.err == context.Canceled {
+		return nil
+	}
+	return b.err
+}
+
+// Offline is a running sls offline, its handlers are served on URL
+type Offline struct {
+	URL        string
+	Port       int
+	LambdaPort int
+
+	process *background
+}
+
+// Wait blocks until sls offline exits, returning its error
+func (o *Offline) Wait() error {
+	return o.process.wait()
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// StartOffline runs sls offline start in the background on free ports and returns once it's serving, it runs until
+// StopOffline or until ctx is done
+func (w *Wrapper) StartOffline(ctx context.Context) (*Offline, error) {
+	w.processMu.Lock()
+	defer w.processMu.Unlock()
+	if w.offline != nil {
+		return nil, errors.New("sls offline is already running")
+	}
+
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	lambdaPort, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Offline{Port: port, LambdaPort: lambdaPort}
+	var url string
+	onLine := func(line string) bool {
+		if m := offlineReadyRe.FindStringSubmatch(line); m != nil && url == "" {
+			url = strings.TrimSuffix(m[1], "/")
+			return true
+		}
+		return false
+	}
+	o.process, err = w.startBackground(ctx, offlineStartTimeout, onLine, "offline", "start", "--httpPort", strconv.Itoa(port), "--lambdaPort", strconv.Itoa(lambdaPort))
+	if err != nil {
+		return nil, err
+	}
+	o.URL = url
+	w.offline = o
+	return o, nil
+}
+
+// StopOffline interrupts the running sls offline and waits for it to exit
+func (w *Wrapper) StopOffline() error {
+	w.processMu.Lock()
+	o := w.offline
+	w.offline = nil
+	w.processMu.Unlock()
+	if o == nil {
+		return nil
+	}
+	return o.process.stop()
+}
@@ -0,0 +1,75 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// StackOutputs maps the stack's output keys to their values
+type StackOutputs map[string]string
+
+// Outputs returns the outputs of the deployed stack, including the ones declared in the config's resources
+func (w *Wrapper) Outputs(ctx context.Context) (StackOutputs, error) {
+	stack, err := w.describeStack(ctx, w.StackName())
+	if err != nil {
+		return nil, err
+	}
+	if stack == nil {
+		return nil, fmt.Errorf("stack %s doesn't exist", w.StackName())
+	}
+
+	outputs := make(StackOutputs, len(stack.Outputs))
+	for _, o := range stack.Outputs {
+		outputs[o.OutputKey] = o.OutputValue
+	}
+	return outputs, nil
+}
+
+func (o StackOutputs) String(key string) (string, error) {
+	v, ok := o[key]
+	if !ok {
+		return "", fmt.Errorf("stack has no output %s", key)
+	}
+	return v, nil
+}
+
+func (o StackOutputs) Int(key string) (int, error) {
+	v, err := o.String(key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("output %s: %v", key, err)
+	}
+	return n, nil
+}
+
+func (o StackOutputs) Bool(key string) (bool, error) {
+	v, err := o.String(key)
+	if err != nil {
+		return false, err
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("output %s: %v", key, err)
+	}
+	return b, nil
+}
+
+func (o StackOutputs) URL(key string) (*url.URL, error) {
+	v, err := o.String(key)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(v)
+	if err != nil {
+		return nil, fmt.Errorf("output %s: %v", key, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("output %s is not an absolute url: %s", key, v)
+	}
+	return u, nil
+}
@@ -0,0 +1,152 @@
+package sls
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Package builds the stack and runs sls package into outDir, the result can be deployed to other stages with DeployPackageDir
+func (w *Wrapper) Package(ctx context.Context, outDir string) ([]BuildResult, error) {
+	outDir, err := filepath.Abs(outDir)
+	if err != nil {
+		return nil, err
+	}
+
+	defer w.lockDir()()
+
+	results, err := w.build(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = w.validateArtifactSizes(results)
+	if err != nil {
+		return nil, err
+	}
+	err = w.packageResultsInto(ctx, results, outDir)
+	if err != nil {
+		return nil, err
+	}
+	return packageResults(outDir)
+}
+
+// packageResultsInto runs sls package into outDir against the config pointing at the built artifacts
+func (w *Wrapper) packageResultsInto(ctx context.Context, results []BuildResult, outDir string) error {
+	packager, done, err := w.packaged(results)
+	if err != nil {
+		return err
+	}
+	defer done()
+	_, err = packager.execSlsCmd(ctx, w.yamlDirPath, "package", "--package", outDir)
+	return err
+}
+
+// stackPackage is a built stack packaged for a deploy, deployer runs sls against the config it was packaged with
+type stackPackage struct {
+	results  []BuildResult
+	deployer *Wrapper
+	dir      string
+	done     func()
+}
+
+func (p *stackPackage) remove() {
+	os.RemoveAll(p.dir)
+	p.done()
+}
+
+// buildPackage builds the stack and packages it into a dir of its own while holding the project dir, which the
+// builders and the framework's packaging share between suffixes and regions, so only this part of concurrent deploys
+// of the same project is serialized
+func (w *Wrapper) buildPackage(ctx context.Context, phases *phaseTimer) (*stackPackage, error) {
+	defer w.lockDir()()
+
+	results, err := w.build(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = w.validateArtifactSizes(results)
+	if err != nil {
+		return nil, err
+	}
+	deployer, done, err := w.packaged(results)
+	if err != nil {
+		return nil, err
+	}
+
+	phases.start("package")
+	dir, err := deployer.packageStack(ctx)
+	if err != nil {
+		done()
+		return nil, err
+	}
+	return &stackPackage{results: results, deployer: deployer, dir: dir, done: done}, nil
+}
+
+// packageStack runs sls package into a new dir named after the suffix, checking the resource count when
+// ResourcePreflight is set
+func (w *Wrapper) packageStack(ctx context.Context) (string, error) {
+	packageDir, err := ioutil.TempDir("", "sls-package-"+w.suffix+"-")
+	if err != nil {
+		return "", err
+	}
+	_, err = w.execSlsCmd(ctx, w.yamlDirPath, "package", "--package", packageDir)
+	if err == nil && w.ResourcePreflight {
+		var t *template
+		t, err = readTemplate(filepath.Join(packageDir, packagedTemplateName))
+		if err == nil {
+			err = w.countResources(t).check(w.StackName())
+		}
+	}
+	if err != nil {
+		os.RemoveAll(packageDir)
+		return "", err
+	}
+	return packageDir, nil
+}
+
+func (w *Wrapper) deployPackage(ctx context.Context, packageDir string) (*DeployResult, error) {
+	packageDir, err := filepath.Abs(packageDir)
+	if err != nil {
+		return nil, err
+	}
+	out, err := w.execDeploy(ctx, append(w.deployArgs(), "--package", packageDir)...)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := packageResults(packageDir)
+	if err != nil {
+		return nil, err
+	}
+	err = w.writeManifest(ctx, results)
+	if err != nil {
+		return nil, err
+	}
+	result := w.deployResult(ctx, out, results)
+	err = w.recordDeployment(result)
+	if err != nil {
+		return nil, err
+	}
+	w.pruneAfterDeploy(ctx)
+	return result, nil
+}
+
+func packageResults(packageDir string) ([]BuildResult, error) {
+	zips, err := filepath.Glob(filepath.Join(packageDir, "*.zip"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(zips)
+
+	var results []BuildResult
+	for _, z := range zips {
+		size, sum, err := fileChecksum(z)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, BuildResult{Runtime: "package", Artifact: z, Size: size, Sha256: sum})
+	}
+	return results, nil
+}
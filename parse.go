@@ -0,0 +1,90 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var ansiRe = regexp.MustCompile("\x1b\\[[0-9;]*[a-zA-Z]")
+
+// slsLines splits sls output into lines without colors and the "Serverless: " log prefix
+func slsLines(out string) []string {
+	var lines []string
+	for _, line := range strings.Split(ansiRe.ReplaceAllString(out, ""), "\n") {
+		line = strings.TrimRight(line, "\r ")
+		line = strings.TrimPrefix(line, "Serverless: ")
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// outputCommand is an sls command whose output one of the parsers reads
+type outputCommand struct {
+	args    []string
+	needKey bool
+	parse   func(out string) (interface{}, error)
+}
+
+var outputCommands = map[string]outputCommand{
+	"info": {args: []string{"info"}, parse: func(out string) (interface{}, error) {
+		return parseServiceInfo(out), nil
+	}},
+	"deploy-list": {args: []string{"deploy", "list"}, parse: func(out string) (interface{}, error) {
+		return parseDeployments(out), nil
+	}},
+	"deploy-list-functions": {args: []string{"deploy", "list", "functions"}, parse: func(out string) (interface{}, error) {
+		return parseFunctionVersions(out), nil
+	}},
+	"invoke": {args: []string{"invoke", "--log", "-f"}, needKey: true, parse: func(out string) (interface{}, error) {
+		return parseInvokeOutput(out), nil
+	}},
+	"logs": {args: []string{"logs", "-f"}, needKey: true, parse: func(out string) (interface{}, error) {
+		return parseLogs(out), nil
+	}},
+	"metrics": {args: []string{"metrics", "-f"}, needKey: true, parse: func(out string) (interface{}, error) {
+		return parseMetrics(out)
+	}},
+	// reports reads the REPORT lines of the same logs, by request id
+	"reports": {args: []string{"logs", "-f"}, needKey: true, parse: func(out string) (interface{}, error) {
+		return parseReports(out), nil
+	}},
+}
+
+// OutputKinds lists the sls commands CaptureOutput runs and ParseOutput reads
+func OutputKinds() []string {
+	kinds := make([]string, 0, len(outputCommands))
+	for kind := range outputCommands {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// ParseOutput runs the parser of an output kind, it's how the parsers can be checked against recorded outputs
+func ParseOutput(kind string, out string) (interface{}, error) {
+	c, ok := outputCommands[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown output kind %s", kind)
+	}
+	return c.parse(out)
+}
+
+// CaptureOutput runs the sls command of an output kind and returns its raw output, key is the function the invoke,
+// logs and metrics commands are for
+func (w *Wrapper) CaptureOutput(ctx context.Context, kind string, key string) (string, error) {
+	c, ok := outputCommands[kind]
+	if !ok {
+		return "", fmt.Errorf("unknown output kind %s", kind)
+	}
+	args := append([]string{}, c.args...)
+	if c.needKey {
+		if _, ok := w.stack.Functions[key]; !ok {
+			return "", fmt.Errorf("function %s is not defined in %s", key, w.configName)
+		}
+		args = append(args, key)
+	}
+	return w.execSlsCmdRetries(ctx, w.yamlDirPath, 0, args...)
+}
@@ -0,0 +1,109 @@
+package sls
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	globsMu sync.Mutex
+	globs   = make(map[string]*regexp.Regexp)
+)
+
+// globRegexp translates the globs of package patterns, ** matches across directories and {a,b} alternatives
+func globRegexp(pattern string) *regexp.Regexp {
+	globsMu.Lock()
+	defer globsMu.Unlock()
+	if re, ok := globs[pattern]; ok {
+		return re
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	braces := 0
+	glob := strings.TrimPrefix(pattern, "./")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '{':
+			b.WriteString("(?:")
+			braces++
+		case c == '}' && braces > 0:
+			b.WriteString(")")
+			braces--
+		case c == ',' && braces > 0:
+			b.WriteString("|")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	// a malformed pattern matches nothing, as the framework's globbing skips it
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		re = regexp.MustCompile(`^\b$`)
+	}
+	globs[pattern] = re
+	return re
+}
+
+// packages reports whether the framework packages the file at rel, a slash separated path relative to the service
+// dir, applying exclude, include and then patterns in order, where later patterns override earlier ones
+func (p PackageMeta) packages(rel string) bool {
+	packaged := true
+	for _, pattern := range p.Exclude {
+		if globRegexp(pattern).MatchString(rel) {
+			packaged = false
+		}
+	}
+	for _, pattern := range p.Include {
+		if globRegexp(pattern).MatchString(rel) {
+			packaged = true
+		}
+	}
+	for _, pattern := range p.Patterns {
+		if strings.HasPrefix(pattern, "!") {
+			if globRegexp(pattern[1:]).MatchString(rel) {
+				packaged = false
+			}
+		} else if globRegexp(pattern).MatchString(rel) {
+			packaged = true
+		}
+	}
+	return packaged
+}
+
+// functionPackage combines the service's package options with the function's, which the framework applies after them
+func (w *Wrapper) functionPackage(f FunctionMeta) PackageMeta {
+	service := w.stack.Package
+	return PackageMeta{
+		Patterns: append(append([]string{}, service.Patterns...), f.Package.Patterns...),
+		Exclude:  append(append([]string{}, service.Exclude...), f.Package.Exclude...),
+		Include:  append(append([]string{}, service.Include...), f.Package.Include...),
+	}
+}
+
+// packagesFile reports whether the file at rel goes into the service's package or any function's
+func (w *Wrapper) packagesFile(rel string) bool {
+	if w.stack.Package.packages(rel) {
+		return true
+	}
+	for _, f := range w.stack.Functions {
+		if w.functionPackage(f).packages(rel) {
+			return true
+		}
+	}
+	return false
+}
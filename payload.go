@@ -0,0 +1,59 @@
+package sls
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// DefaultPayloadSizes go from 1KB up to lambda's 6MB limit on synchronous payloads
+var DefaultPayloadSizes = []int{1 << 10, 10 << 10, 100 << 10, 256 << 10, 1 << 20, 3 << 20, 6 << 20}
+
+type PayloadSizeResult struct {
+	Size   int
+	Stats  LatencyStats
+	Errors map[ErrorCategory]int
+	// Result holds the invocations, their errors show e.g. where the payload got too large
+	Result *InvokeNResult
+}
+
+// sizedPayload is a json object of exactly size bytes, {"data":"xxx..."}
+func sizedPayload(size int) []byte {
+	const overhead = len(`{"data":""}`)
+	if size < overhead {
+		size = overhead
+	}
+	var b bytes.Buffer
+	b.Grow(size)
+	b.WriteString(`{"data":"`)
+	b.Write(bytes.Repeat([]byte("x"), size-overhead))
+	b.WriteString(`"}`)
+	return b.Bytes()
+}
+
+// PayloadSweep invokes the function n times one after another with payloads of each size, DefaultPayloadSizes when
+// none are given, the payloads are json objects with a single string field padded to the size
+func (w *Wrapper) PayloadSweep(ctx context.Context, key string, sizes []int, n int) ([]PayloadSizeResult, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	if len(sizes) == 0 {
+		sizes = DefaultPayloadSizes
+	}
+
+	var results []PayloadSizeResult
+	for _, size := range sizes {
+		result, err := w.InvokeN(ctx, key, sizedPayload(size), n, 1)
+		if err != nil {
+			return nil, err
+		}
+		r := PayloadSizeResult{Size: size, Stats: result.Stats, Errors: make(map[ErrorCategory]int), Result: result}
+		for _, inv := range result.Invocations {
+			if inv.Err != nil {
+				r.Errors[inv.Category]++
+			}
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
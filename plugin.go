@@ -0,0 +1,143 @@
+package sls
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+type Plugin struct {
+	Name string
+	// Version is the installed version, empty when the plugin isn't in node_modules
+	Version string
+}
+
+// pluginVersion reads the version of an installed plugin, local plugins referenced by path have none
+func (w *Wrapper) pluginVersion(name string) string {
+	data, err := ioutil.ReadFile(filepath.Join(w.yamlDirPath, "node_modules", name, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Version string `json:"version"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return ""
+	}
+	return pkg.Version
+}
+
+// reloadPlugins rereads the plugins, which sls plugin edits in the config
+func (w *Wrapper) reloadPlugins() error {
+	stack, err := parseConfigFile(w.provider, w.configPath())
+	if err != nil {
+		return err
+	}
+	w.stack.Plugins = stack.Plugins
+	return nil
+}
+
+// PluginList lists the config's plugins with their installed versions
+func (w *Wrapper) PluginList() ([]Plugin, error) {
+	err := w.reloadPlugins()
+	if err != nil {
+		return nil, err
+	}
+	plugins := make([]Plugin, 0, len(w.stack.Plugins))
+	for _, name := range w.stack.Plugins {
+		plugins = append(plugins, Plugin{Name: name, Version: w.pluginVersion(name)})
+	}
+	return plugins, nil
+}
+
+// installDependencies runs npm install when the project has a package.json but nothing was installed yet, sls plugin
+// install would otherwise leave node_modules with only the plugin
+func (w *Wrapper) installDependencies(ctx context.Context) error {
+	if !fileExists(filepath.Join(w.yamlDirPath, "package.json")) || fileExists(filepath.Join(w.yamlDirPath, "node_modules")) {
+		return nil
+	}
+	_, err := w.execCmd(ctx, []string{}, w.yamlDirPath, "npm", "install")
+	return err
+}
+
+// PluginInstall installs a plugin with npm and adds it to the config, name may include an @version
+func (w *Wrapper) PluginInstall(ctx context.Context, name string) error {
+	err := w.installDependencies(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = w.execSlsCmdRetries(ctx, w.yamlDirPath, 0, "plugin", "install", "--name", name)
+	if err != nil {
+		return err
+	}
+	return w.reloadPlugins()
+}
+
+// PluginUninstall removes a plugin from the config and node_modules
+func (w *Wrapper) PluginUninstall(ctx context.Context, name string) error {
+	_, err := w.execSlsCmdRetries(ctx, w.yamlDirPath, 0, "plugin", "uninstall", "--name", pluginName(name))
+	if err != nil {
+		return err
+	}
+	return w.reloadPlugins()
+}
+
+// pluginName strips the version from name@version, keeping the @ of scoped packages
+func pluginName(name string) string {
+	if i := strings.LastIndex(name, "@"); i > 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// localPlugin reports plugins loaded from a path in the project rather than from npm
+func localPlugin(name string) bool {
+	return strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../") || filepath.IsAbs(name)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// missingPlugins lists the config's npm plugins that aren't in node_modules
+func (w *Wrapper) missingPlugins() []string {
+	var missing []string
+	for _, name := range w.stack.Plugins {
+		if !localPlugin(name) && !fileExists(filepath.Join(w.yamlDirPath, "node_modules", name)) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// installMissingPlugins installs the project's dependencies and then whatever plugins are still missing as dev
+// dependencies, failing before installing anything when a missing plugin isn't allowed
+func (w *Wrapper) installMissingPlugins(ctx context.Context) error {
+	if len(w.missingPlugins()) == 0 {
+		return nil
+	}
+	err := w.installDependencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	missing := w.missingPlugins()
+	if len(missing) == 0 {
+		return nil
+	}
+	for _, name := range missing {
+		if containsString(w.PluginDenyList, name) || (len(w.PluginAllowList) > 0 && !containsString(w.PluginAllowList, name)) {
+			return fmt.Errorf("plugin %s isn't installed and isn't allowed to be installed automatically", name)
+		}
+	}
+	_, err = w.execCmd(ctx, []string{}, w.yamlDirPath, "npm", append([]string{"install", "--save-dev"}, missing...)...)
+	return err
+}
@@ -0,0 +1,116 @@
+package sls
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+var zipMagic = []byte("PK\x03\x04")
+
+func (w *Wrapper) platformFunctions(platform string) []string {
+	var keys []string
+	for key, f := range w.stack.Functions {
+		if runtimePlatform(w.functionRuntime(f)) == platform {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// prebuiltPlatform reports whether every function of a platform was given a prebuilt artifact
+func (w *Wrapper) prebuiltPlatform(platform string) bool {
+	keys := w.platformFunctions(platform)
+	if len(keys) == 0 {
+		return false
+	}
+	for _, key := range keys {
+		if _, ok := w.PrebuiltArtifacts[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *Wrapper) injectPrebuiltArtifacts(functions map[string]bool) ([]BuildResult, error) {
+	keys := make([]string, 0, len(w.PrebuiltArtifacts))
+	for key := range w.PrebuiltArtifacts {
+		if functions == nil || functions[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var results []BuildResult
+	for _, key := range keys {
+		start := time.Now()
+		dst, err := w.injectPrebuiltArtifact(key, w.PrebuiltArtifacts[key])
+		if err != nil {
+			return nil, err
+		}
+		size, sum, err := fileChecksum(dst)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, BuildResult{
+			Runtime:   "prebuilt",
+			Functions: []string{key},
+			Artifact:  dst,
+			Size:      size,
+			Sha256:    sum,
+			Duration:  time.Since(start),
+		})
+	}
+	return results, nil
+}
+
+// injectPrebuiltArtifact places the artifact where the function's package.artifact points, plain binaries are zipped
+func (w *Wrapper) injectPrebuiltArtifact(key string, src string) (string, error) {
+	f, ok := w.stack.Functions[key]
+	if !ok {
+		return "", fmt.Errorf("prebuilt artifact given for unknown function %s", key)
+	}
+	if f.Package.Artifact == "" {
+		return "", fmt.Errorf("function %s has no package.artifact to place the prebuilt artifact %s at", key, src)
+	}
+	dst := w.artifactRef(f.Package.Artifact)
+
+	isZip, err := hasZipMagic(src)
+	if err != nil {
+		return "", err
+	}
+	if isZip {
+		return dst, copyFile(src, dst)
+	}
+
+	name := handlerFile(f.Handler)
+	if strings.HasPrefix(w.functionRuntime(f), "provided") {
+		name = bootstrapName
+	}
+	if strings.HasPrefix(w.functionRuntime(f), "go") {
+		name = f.Handler
+	}
+	return dst, zipArtifact(dst, []artifactFile{{src: src, name: name, mode: 0755}}, w.artifactModTime())
+}
+
+func hasZipMagic(p string) (bool, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(zipMagic))
+	_, err = io.ReadFull(f, magic)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(magic, zipMagic), nil
+}
@@ -0,0 +1,40 @@
+package sls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gopkg.in/yaml.v2"
+	"strings"
+)
+
+type ResolvedConfig struct {
+	Stack *ServiceStack
+	Raw   map[string]interface{}
+}
+
+// PrintConfig returns the configuration as resolved by the framework itself, with every variable substituted
+func (w *Wrapper) PrintConfig(ctx context.Context) (*ResolvedConfig, error) {
+	out, err := w.execSlsCmd(ctx, w.yamlDirPath, "print", "--format", "json")
+	if err != nil {
+		return nil, err
+	}
+	// older versions log a few lines before the document
+	start := strings.Index(out, "{")
+	if start < 0 {
+		return nil, errors.New("sls print returned no json document")
+	}
+	data := []byte(out[start:])
+
+	config := &ResolvedConfig{Stack: &ServiceStack{}}
+	err = json.Unmarshal(data, &config.Raw)
+	if err != nil {
+		return nil, err
+	}
+	// json is valid yaml, which lets the typed stack reuse the yaml tags
+	err = yaml.Unmarshal(data, config.Stack)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
@@ -0,0 +1,85 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// ForStage returns a wrapper deploying the same suffix to another stage
+func (w *Wrapper) ForStage(stage string) (*Wrapper, error) {
+	other, err := w.WithSuffix(w.suffix)
+	if err != nil {
+		return nil, err
+	}
+	other.Opts["stage"] = stage
+	return other, nil
+}
+
+// Promote builds the stack once and packages it for fromStage and toStage from the same build, then deploys toStage's
+// package. The framework renders the stage into the template, so each stage is packaged, but both packages are made of
+// the one build's artifacts, which must come out identical
+func (w *Wrapper) Promote(ctx context.Context, fromStage string, toStage string) (*DeployResult, error) {
+	from, err := w.ForStage(fromStage)
+	if err != nil {
+		return nil, err
+	}
+	to, err := w.ForStage(toStage)
+	if err != nil {
+		return nil, err
+	}
+	to.promotedFrom = fromStage
+
+	dir, err := ioutil.TempDir("", "sls-promote")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	unlock, err := to.lockStack(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	fromDir := filepath.Join(dir, fromStage)
+	toDir := filepath.Join(dir, toStage)
+	unlockDir := w.lockDir()
+	results, err := from.build(ctx, nil)
+	if err == nil {
+		err = from.validateArtifactSizes(results)
+	}
+	if err == nil {
+		err = from.packageResultsInto(ctx, results, fromDir)
+	}
+	if err == nil {
+		err = to.packageResultsInto(ctx, results, toDir)
+	}
+	unlockDir()
+	if err != nil {
+		return nil, err
+	}
+
+	fromResults, err := packageResults(fromDir)
+	if err != nil {
+		return nil, err
+	}
+	toResults, err := packageResults(toDir)
+	if err != nil {
+		return nil, err
+	}
+	sums := make(map[string]string)
+	for _, r := range fromResults {
+		sums[filepath.Base(r.Artifact)] = r.Sha256
+	}
+	for _, r := range toResults {
+		name := filepath.Base(r.Artifact)
+		if sum, ok := sums[name]; !ok || sum != r.Sha256 {
+			return nil, fmt.Errorf("artifact %s of stage %s differs from stage %s's, the package isn't reproducible", name, toStage, fromStage)
+		}
+	}
+
+	return to.deployPackage(ctx, toDir)
+}
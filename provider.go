@@ -0,0 +1,218 @@
+package sls
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var envRefRe = regexp.MustCompile(`^\$\{env:(\w+)\}$`)
+
+// Provider holds what differs between the framework's providers, it's looked up by the provider name the wrapper
+// was created with
+type Provider interface {
+	Name() string
+	// DeployFlags are added to every sls deploy
+	DeployFlags() []string
+	// CheckCredentials fails when the provider's credentials aren't set up, before anything is deployed
+	CheckCredentials(ctx context.Context, w *Wrapper) error
+	// ParseInfo reads the output of sls info, which every provider plugin formats its own way
+	ParseInfo(out string) *ServiceInfo
+	// ArtifactLimits are the largest zipped and unzipped packages the provider accepts, zero for no limit
+	ArtifactLimits() (zipped int64, unzipped int64)
+	// Cleanup runs after sls remove with its error, to verify the removal or finish what it left behind
+	Cleanup(ctx context.Context, w *Wrapper, removeErr error) error
+}
+
+// ImageProvider is implemented by providers whose image functions are pushed somewhere other than ECR
+type ImageProvider interface {
+	// ImageRepository is used when the wrapper's ImageRepository isn't set
+	ImageRepository(w *Wrapper) string
+	// RegistryLogin logs docker in to the repository's registry, writing the commands' output to log
+	RegistryLogin(ctx context.Context, w *Wrapper, repository string, log io.Writer) error
+}
+
+// RegionProvider is implemented by providers whose regions aren't aws's
+type RegionProvider interface {
+	// DefaultRegion is the region the provider's plugin deploys to when none is configured
+	DefaultRegion() string
+	Regions() []string
+}
+
+// EndpointResolver is implemented by providers whose info output lacks the deployed urls, they're looked up after
+// parsing it
+type EndpointResolver interface {
+	ResolveEndpoints(ctx context.Context, w *Wrapper, info *ServiceInfo) error
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]Provider)
+)
+
+// RegisterProvider makes a provider available to wrappers created for its name, replacing a previous registration
+func RegisterProvider(p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[p.Name()] = p
+}
+
+func lookupProvider(name string) Provider {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	if p, ok := providers[name]; ok {
+		return p
+	}
+	return genericProvider{name: name}
+}
+
+func (w *Wrapper) Provider() Provider {
+	return lookupProvider(w.provider)
+}
+
+// parseInfo parses sls info or deploy output with the provider, resolving the endpoints it lacks
+func (w *Wrapper) parseInfo(ctx context.Context, out string) (*ServiceInfo, error) {
+	provider := w.Provider()
+	info := provider.ParseInfo(out)
+	if resolver, ok := provider.(EndpointResolver); ok {
+		err := resolver.ResolveEndpoints(ctx, w, info)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+// configValue resolves values that are only an ${env:VAR} reference, other variables resolve to nothing
+func configValue(v string) string {
+	if m := envRefRe.FindStringSubmatch(v); m != nil {
+		return os.Getenv(m[1])
+	}
+	if strings.Contains(v, "${") {
+		return ""
+	}
+	return v
+}
+
+// checkRegion fails on regions the provider doesn't have, which its plugin would only report after packaging
+func (w *Wrapper) checkRegion(p RegionProvider) error {
+	region := w.Region()
+	for _, r := range p.Regions() {
+		if r == region {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s isn't a %s region, expected one of %s", region, w.provider, strings.Join(p.Regions(), ", "))
+}
+
+// checkPlugin fails when the config doesn't load the provider's plugin or it isn't installed next to it
+func (w *Wrapper) checkPlugin(plugin string) error {
+	listed := false
+	for _, p := range w.stack.Plugins {
+		if p == plugin {
+			listed = true
+		}
+	}
+	if !listed {
+		return fmt.Errorf("the %s provider needs %s in the plugins of %s", w.provider, plugin, w.configName)
+	}
+	if !fileExists(filepath.Join(w.yamlDirPath, "node_modules", plugin)) {
+		return fmt.Errorf("plugin %s isn't installed in %s", plugin, w.yamlDirPath)
+	}
+	return nil
+}
+
+// credentialsPath is provider.credentials with ~ expanded and relative paths resolved against the yaml dir
+func (w *Wrapper) credentialsPath(def string) (string, error) {
+	path := configValue(w.stack.Provider.Credentials)
+	if path == "" {
+		path = def
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(w.yamlDirPath, path)
+	}
+	return path, nil
+}
+
+// readCredentials reads a section of an ini credentials file, as tencent's and aliyun's plugins use
+func readCredentials(path string, section string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	current := ""
+	found := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			found = found || current == section
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if current == section && len(parts) == 2 {
+			values[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%s has no [%s] section", path, section)
+	}
+	return values, nil
+}
+
+// CheckCredentials checks the provider's credentials are usable
+func (w *Wrapper) CheckCredentials(ctx context.Context) error {
+	return w.Provider().CheckCredentials(ctx, w)
+}
+
+// genericProvider is used for providers nothing is registered for, it only relies on the framework
+type genericProvider struct {
+	name string
+}
+
+func (p genericProvider) Name() string {
+	return p.name
+}
+
+func (genericProvider) DeployFlags() []string {
+	return nil
+}
+
+func (genericProvider) CheckCredentials(ctx context.Context, w *Wrapper) error {
+	return nil
+}
+
+func (genericProvider) ParseInfo(out string) *ServiceInfo {
+	return parseServiceInfo(out)
+}
+
+func (genericProvider) ArtifactLimits() (int64, int64) {
+	return 0, 0
+}
+
+func (genericProvider) Cleanup(ctx context.Context, w *Wrapper, removeErr error) error {
+	return removeErr
+}
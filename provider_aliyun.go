@@ -0,0 +1,181 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	aliyunPlugin      = "serverless-aliyun-function-compute"
+	aliyunCredentials = "~/.aliyuncli/credentials"
+
+	maxAliyunZippedSize   = 50 * 1024 * 1024
+	maxAliyunUnzippedSize = 500 * 1024 * 1024
+)
+
+var (
+	aliyunRegions = []string{
+		"cn-hangzhou", "cn-shanghai", "cn-qingdao", "cn-beijing", "cn-zhangjiakou", "cn-huhehaote", "cn-shenzhen",
+		"cn-chengdu", "cn-hongkong", "ap-southeast-1", "ap-southeast-2", "ap-southeast-3", "ap-southeast-5",
+		"ap-northeast-1", "ap-south-1", "eu-central-1", "eu-west-1", "us-west-1", "us-east-1",
+	}
+	// aliyunNameRe is what function compute accepts as service and function names
+	aliyunNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]{0,63}$`)
+	httpMethods  = map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true, "HEAD": true, "OPTIONS": true, "ANY": true}
+)
+
+func init() {
+	RegisterProvider(aliyunProvider{})
+}
+
+type aliyunProvider struct{}
+
+func (aliyunProvider) Name() string {
+	return "aliyun"
+}
+
+// DeployFlags are empty, the plugin reads its settings from the config
+func (aliyunProvider) DeployFlags() []string {
+	return nil
+}
+
+func (aliyunProvider) DefaultRegion() string {
+	return "cn-shanghai"
+}
+
+func (aliyunProvider) Regions() []string {
+	return aliyunRegions
+}
+
+// aliyunFunctionName is how the plugin names functions, <service>-<stage>-<key> in a service named <service>-<stage>
+func (w *Wrapper) aliyunFunctionName(key string) string {
+	if name := w.stack.Functions[key].Name; name != "" {
+		return name
+	}
+	return w.StackName() + "-" + key
+}
+
+// CheckCredentials checks the region, the plugin, the names function compute would reject and the credentials file
+func (p aliyunProvider) CheckCredentials(ctx context.Context, w *Wrapper) error {
+	err := w.checkRegion(p)
+	if err != nil {
+		return err
+	}
+	err = w.checkPlugin(aliyunPlugin)
+	if err != nil {
+		return err
+	}
+	names := []string{w.StackName()}
+	for _, key := range w.functionKeys() {
+		names = append(names, w.aliyunFunctionName(key))
+	}
+	for _, name := range names {
+		if !aliyunNameRe.MatchString(name) {
+			return fmt.Errorf("%s isn't a valid function compute name, names are up to 64 letters, digits, _ and -", name)
+		}
+	}
+
+	path, err := w.credentialsPath(aliyunCredentials)
+	if err != nil {
+		return err
+	}
+	creds, err := readCredentials(path, "default")
+	if err != nil {
+		return fmt.Errorf("aliyun credentials aren't usable: %v", err)
+	}
+	for _, key := range []string{"aliyun_account_id", "aliyun_access_key_id", "aliyun_access_key_secret"} {
+		if creds[key] == "" {
+			return fmt.Errorf("aliyun credentials in %s are missing %s", path, key)
+		}
+	}
+	return nil
+}
+
+// aliyunEndpoint parses the "METHOD url" lines of api gateway endpoints and the bare urls of http triggers, whose path
+// is /<version>/proxy/<service>/<function>/
+func aliyunEndpoint(line string) (Endpoint, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 2 && httpMethods[strings.ToUpper(fields[0])] && strings.HasPrefix(fields[1], "http") {
+		return Endpoint{Method: strings.ToUpper(fields[0]), URL: fields[1]}, true
+	}
+	if len(fields) != 1 || !strings.HasPrefix(fields[0], "http") {
+		return Endpoint{}, false
+	}
+	e := Endpoint{URL: fields[0]}
+	if u, err := url.Parse(fields[0]); err == nil {
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) >= 4 && parts[1] == "proxy" {
+			e.Function = strings.TrimPrefix(parts[3], parts[2]+"-")
+		}
+	}
+	return e, true
+}
+
+// ParseInfo reads the plugin's info, which lists "- <name>" entries under unindented Functions and Endpoints headers
+func (aliyunProvider) ParseInfo(out string) *ServiceInfo {
+	info := &ServiceInfo{
+		Functions: make(map[string]string),
+		Layers:    make(map[string]string),
+		APIKeys:   make(map[string]string),
+		Outputs:   make(map[string]string),
+	}
+
+	section := ""
+	for _, line := range slsLines(out) {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "Service Information" {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSuffix(trimmed, ":")) {
+		case "functions", "endpoints":
+			section = strings.ToLower(strings.TrimSuffix(trimmed, ":"))
+			continue
+		}
+
+		switch section {
+		case "functions":
+			if strings.HasPrefix(trimmed, "- ") {
+				name := strings.TrimSpace(strings.TrimPrefix(trimmed, "- "))
+				key := name
+				if prefix := info.Service + "-" + info.Stage + "-"; strings.HasPrefix(name, prefix) {
+					key = strings.TrimPrefix(name, prefix)
+				}
+				info.Functions[key] = name
+			}
+			continue
+		case "endpoints":
+			if e, ok := aliyunEndpoint(trimmed); ok {
+				info.Endpoints = append(info.Endpoints, e)
+			}
+			continue
+		}
+
+		key, value, ok := splitKeyValue(trimmed)
+		if !ok {
+			continue
+		}
+		switch key {
+		case "service":
+			info.Service = value
+		case "stage":
+			info.Stage = value
+		case "region":
+			info.Region = value
+		}
+	}
+	if info.Service != "" && info.Stage != "" {
+		info.Stack = info.Service + "-" + info.Stage
+	}
+	return info
+}
+
+func (aliyunProvider) ArtifactLimits() (int64, int64) {
+	return maxAliyunZippedSize, maxAliyunUnzippedSize
+}
+
+func (aliyunProvider) Cleanup(ctx context.Context, w *Wrapper, removeErr error) error {
+	return removeErr
+}
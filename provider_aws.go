@@ -0,0 +1,73 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+func init() {
+	RegisterProvider(awsProvider{})
+}
+
+type awsProvider struct{}
+
+func (awsProvider) Name() string {
+	return "aws"
+}
+
+// DeployFlags disables transfer acceleration, which needs a bucket setting most deployment buckets don't have
+func (awsProvider) DeployFlags() []string {
+	return []string{"--no-aws-s3-accelerate"}
+}
+
+// checkedCredentials remembers the credentials sts accepted, so only the first deploy with them pays for the call
+var checkedCredentials = struct {
+	sync.Mutex
+	keys map[string]bool
+}{keys: make(map[string]bool)}
+
+func (awsProvider) CheckCredentials(ctx context.Context, w *Wrapper) error {
+	key := strings.Join([]string{w.Opts["aws-profile"], os.Getenv("AWS_PROFILE"), os.Getenv("AWS_ACCESS_KEY_ID"),
+		w.LocalStackEndpoint, w.Region()}, "\x00")
+	checkedCredentials.Lock()
+	checked := checkedCredentials.keys[key]
+	checkedCredentials.Unlock()
+	if checked {
+		return nil
+	}
+
+	var identity struct {
+		Account string
+		Arn     string
+	}
+	err := w.awsCmd(ctx, &identity, "sts", "get-caller-identity")
+	if err != nil {
+		return fmt.Errorf("aws credentials aren't usable: %v", err)
+	}
+	checkedCredentials.Lock()
+	checkedCredentials.keys[key] = true
+	checkedCredentials.Unlock()
+	return nil
+}
+
+func (awsProvider) ParseInfo(out string) *ServiceInfo {
+	return parseServiceInfo(out)
+}
+
+func (awsProvider) ArtifactLimits() (int64, int64) {
+	return maxZippedSize, maxUnzippedSize
+}
+
+// Cleanup waits for CloudFormation to delete the stack and forces stuck removals, as VerifyRemoval and ForceRemoval ask
+func (awsProvider) Cleanup(ctx context.Context, w *Wrapper, err error) error {
+	if err == nil && (w.VerifyRemoval || w.ForceRemoval) {
+		err = w.waitStackDeleted(ctx)
+	}
+	if err != nil && w.ForceRemoval && ctx.Err() == nil {
+		err = w.forceRemove(ctx, err)
+	}
+	return err
+}
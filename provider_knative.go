@@ -0,0 +1,184 @@
+package sls
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+const defaultKnativeNamespace = "default"
+
+func init() {
+	RegisterProvider(knativeProvider{})
+}
+
+type knativeProvider struct{}
+
+func (knativeProvider) Name() string {
+	return "knative"
+}
+
+func (knativeProvider) DeployFlags() []string {
+	return nil
+}
+
+// KnativeNamespace is the kubernetes namespace the functions are deployed to, set by provider.namespace or the
+// namespace option
+func (w *Wrapper) KnativeNamespace() string {
+	if ns, ok := w.Opts["namespace"]; ok {
+		return ns
+	}
+	if ns := w.stack.Provider.Namespace; ns != "" && !strings.Contains(ns, "${") {
+		return ns
+	}
+	return defaultKnativeNamespace
+}
+
+// kubectl runs kubectl against the service's namespace and decodes its json output into out
+func (w *Wrapper) kubectl(ctx context.Context, out interface{}, args ...string) error {
+	args = append(args, "--namespace", w.KnativeNamespace(), "--output", "json")
+	var stderr bytes.Buffer
+	resp, err := w.execCmdOutput(ctx, []string{}, w.yamlDirPath, ioutil.Discard, &stderr, "kubectl", args...)
+	if err != nil {
+		return fmt.Errorf("kubectl %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	if out == nil || resp == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(resp), out)
+}
+
+type knativeDocker struct {
+	registry string
+	username string
+	password string
+}
+
+func (w *Wrapper) knativeDocker() knativeDocker {
+	docker := w.stack.Provider.Docker
+	return knativeDocker{
+		registry: configValue(docker.Registry),
+		username: configValue(docker.Username),
+		password: configValue(docker.Password),
+	}
+}
+
+type knativeService struct {
+	Metadata struct {
+		Name string
+	}
+	Status struct {
+		URL string
+	}
+}
+
+func (w *Wrapper) knativeServices(ctx context.Context) ([]knativeService, error) {
+	var list struct {
+		Items []knativeService
+	}
+	err := w.kubectl(ctx, &list, "get", "ksvc")
+	return list.Items, err
+}
+
+// CheckCredentials checks the current kube context reaches a cluster with knative serving installed, and that there
+// are docker credentials when functions are built into images
+func (knativeProvider) CheckCredentials(ctx context.Context, w *Wrapper) error {
+	_, err := w.knativeServices(ctx)
+	if err != nil {
+		return fmt.Errorf("knative cluster isn't usable: %v", err)
+	}
+	if len(w.imageFunctions()) > 0 && w.ImageRepository == "" && w.knativeDocker().username == "" {
+		return fmt.Errorf("knative image functions need provider.docker.username or an ImageRepository")
+	}
+	return nil
+}
+
+func (knativeProvider) ParseInfo(out string) *ServiceInfo {
+	info := parseServiceInfo(out)
+	for _, line := range slsLines(out) {
+		if key, value, ok := splitKeyValue(strings.TrimSpace(line)); ok && key == "namespace" {
+			info.Namespace = value
+		}
+	}
+	return info
+}
+
+// knativeServiceName is how the plugin names a function's service, <service>-<stage>-<key>
+func (w *Wrapper) knativeServiceName(key string) string {
+	return w.StackName() + "-" + key
+}
+
+// ResolveEndpoints reads the functions' urls from their services' status, the plugin's output doesn't always list them
+func (knativeProvider) ResolveEndpoints(ctx context.Context, w *Wrapper, info *ServiceInfo) error {
+	services, err := w.knativeServices(ctx)
+	if err != nil {
+		return err
+	}
+	if info.Namespace == "" {
+		info.Namespace = w.KnativeNamespace()
+	}
+
+	urls := make(map[string]string)
+	for _, s := range services {
+		urls[s.Metadata.Name] = s.Status.URL
+	}
+	endpoints := info.Endpoints[:0]
+	for _, e := range info.Endpoints {
+		if e.Function == "" {
+			endpoints = append(endpoints, e)
+		}
+	}
+	for _, key := range w.functionKeys() {
+		name := w.knativeServiceName(key)
+		url, ok := urls[name]
+		if !ok {
+			continue
+		}
+		info.Functions[key] = name
+		if url != "" {
+			endpoints = append(endpoints, Endpoint{Function: key, URL: url})
+		}
+	}
+	info.Endpoints = endpoints
+	return nil
+}
+
+// ImageRepository is <registry>/<username>/<service>, the registry defaults to docker hub
+func (knativeProvider) ImageRepository(w *Wrapper) string {
+	docker := w.knativeDocker()
+	if docker.username == "" {
+		return ""
+	}
+	repository := docker.username + "/" + w.StackId()
+	if docker.registry != "" {
+		repository = strings.TrimSuffix(docker.registry, "/") + "/" + repository
+	}
+	return repository
+}
+
+func (knativeProvider) RegistryLogin(ctx context.Context, w *Wrapper, repository string, log io.Writer) error {
+	docker := w.knativeDocker()
+	if docker.password == "" {
+		// rely on an existing docker login
+		return nil
+	}
+	args := []string{"login", "--username", docker.username, "--password-stdin"}
+	if docker.registry != "" {
+		args = append(args, docker.registry)
+	}
+	// the password must not end up in the build log
+	_, err := w.execCmdIO(ctx, []string{}, w.yamlDirPath, strings.NewReader(docker.password), log, log, "docker", args...)
+	return err
+}
+
+func (knativeProvider) ArtifactLimits() (int64, int64) {
+	return 0, 0
+}
+
+func (knativeProvider) Cleanup(ctx context.Context, w *Wrapper, removeErr error) error {
+	return removeErr
+}
@@ -0,0 +1,177 @@
+package sls
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxActionSize is openwhisk's default limit on action code
+const maxActionSize = 48 * 1024 * 1024
+
+func init() {
+	RegisterProvider(openwhiskProvider{})
+}
+
+// OpenWhiskProps are the wsk cli's properties, which the framework's openwhisk plugin authenticates with
+type OpenWhiskProps struct {
+	APIHost   string
+	Auth      string
+	Namespace string
+}
+
+// ReadOpenWhiskProps reads the properties from WSK_CONFIG_FILE or ~/.wskprops, the OW_APIHOST, OW_AUTH and
+// OW_NAMESPACE variables override them as they do for the plugin
+func ReadOpenWhiskProps() (*OpenWhiskProps, error) {
+	path := os.Getenv("WSK_CONFIG_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, ".wskprops")
+	}
+
+	props := &OpenWhiskProps{}
+	f, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			parts := strings.SplitN(strings.TrimSpace(scanner.Text()), "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			switch parts[0] {
+			case "APIHOST":
+				props.APIHost = parts[1]
+			case "AUTH":
+				props.Auth = parts[1]
+			case "NAMESPACE":
+				props.Namespace = parts[1]
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	for env, prop := range map[string]*string{"OW_APIHOST": &props.APIHost, "OW_AUTH": &props.Auth, "OW_NAMESPACE": &props.Namespace} {
+		if v := os.Getenv(env); v != "" {
+			*prop = v
+		}
+	}
+	return props, nil
+}
+
+// Validate checks the properties are complete, the auth key is "<uuid>:<key>"
+func (p *OpenWhiskProps) Validate() error {
+	if p.APIHost == "" {
+		return fmt.Errorf("openwhisk api host isn't set, set APIHOST in .wskprops or OW_APIHOST")
+	}
+	if p.Auth == "" {
+		return fmt.Errorf("openwhisk auth isn't set, set AUTH in .wskprops or OW_AUTH")
+	}
+	if parts := strings.SplitN(p.Auth, ":", 2); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("openwhisk auth isn't of the form <uuid>:<key>")
+	}
+	return nil
+}
+
+type openwhiskProvider struct{}
+
+func (openwhiskProvider) Name() string {
+	return "openwhisk"
+}
+
+func (openwhiskProvider) DeployFlags() []string {
+	return nil
+}
+
+func (openwhiskProvider) CheckCredentials(ctx context.Context, w *Wrapper) error {
+	props, err := ReadOpenWhiskProps()
+	if err != nil {
+		return err
+	}
+	return props.Validate()
+}
+
+// actionKey maps an action name back to its function key, the plugin names actions <service>_<key> by default
+func actionKey(service string, action string) string {
+	if service != "" && strings.HasPrefix(action, service+"_") {
+		return strings.TrimPrefix(action, service+"_")
+	}
+	return action
+}
+
+// ParseInfo reads the openwhisk plugin's info, whose sections are unindented lists under "actions:",
+// "endpoints (api-gw):" and "endpoints (web actions):", web action urls end in the action's name
+func (openwhiskProvider) ParseInfo(out string) *ServiceInfo {
+	info := &ServiceInfo{
+		Functions: make(map[string]string),
+		Layers:    make(map[string]string),
+		APIKeys:   make(map[string]string),
+		Outputs:   make(map[string]string),
+	}
+
+	section := ""
+	for _, line := range slsLines(out) {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "Service Information" || strings.HasPrefix(trimmed, "**") {
+			continue
+		}
+		if strings.HasSuffix(trimmed, ":") {
+			section = strings.TrimSuffix(trimmed, ":")
+			continue
+		}
+
+		switch section {
+		case "actions":
+			// actions are listed space separated
+			for _, action := range strings.Fields(trimmed) {
+				info.Functions[actionKey(info.Service, action)] = action
+			}
+			continue
+		case "endpoints (api-gw)":
+			if fields := strings.Fields(trimmed); len(fields) == 2 {
+				info.Endpoints = append(info.Endpoints, Endpoint{Method: fields[0], URL: fields[1]})
+			}
+			continue
+		case "endpoints (web actions)":
+			if u, err := url.Parse(trimmed); err == nil {
+				action := strings.TrimSuffix(filepath.Base(u.Path), filepath.Ext(u.Path))
+				info.Endpoints = append(info.Endpoints, Endpoint{Function: actionKey(info.Service, action), URL: trimmed})
+			}
+			continue
+		}
+
+		key, value, ok := splitKeyValue(trimmed)
+		if !ok {
+			continue
+		}
+		switch key {
+		case "service":
+			info.Service = value
+		case "namespace":
+			info.Namespace = value
+		case "platform":
+			info.Region = value
+		}
+	}
+	return info
+}
+
+func (openwhiskProvider) ArtifactLimits() (int64, int64) {
+	return maxActionSize, 0
+}
+
+func (openwhiskProvider) Cleanup(ctx context.Context, w *Wrapper, removeErr error) error {
+	return removeErr
+}
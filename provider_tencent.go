@@ -0,0 +1,86 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+const (
+	tencentPlugin      = "serverless-tencent-scf"
+	tencentCredentials = "~/credentials"
+
+	maxTencentZippedSize   = 50 * 1024 * 1024
+	maxTencentUnzippedSize = 500 * 1024 * 1024
+)
+
+var tencentRegions = []string{
+	"ap-guangzhou", "ap-shanghai", "ap-beijing", "ap-chengdu", "ap-chongqing", "ap-nanjing", "ap-hongkong",
+	"ap-singapore", "ap-mumbai", "ap-seoul", "ap-bangkok", "ap-tokyo", "na-siliconvalley", "na-ashburn", "na-toronto",
+	"eu-frankfurt", "eu-moscow", "ap-shanghai-fsi", "ap-shenzhen-fsi",
+}
+
+func init() {
+	RegisterProvider(tencentProvider{})
+}
+
+type tencentProvider struct{}
+
+func (tencentProvider) Name() string {
+	return "tencent"
+}
+
+func (tencentProvider) DeployFlags() []string {
+	return nil
+}
+
+func (tencentProvider) DefaultRegion() string {
+	return "ap-guangzhou"
+}
+
+func (tencentProvider) Regions() []string {
+	return tencentRegions
+}
+
+// CheckCredentials checks the region, the plugin and the credentials file, the TENCENT_SECRET_ID and
+// TENCENT_SECRET_KEY variables are used instead when both are set
+func (p tencentProvider) CheckCredentials(ctx context.Context, w *Wrapper) error {
+	err := w.checkRegion(p)
+	if err != nil {
+		return err
+	}
+	err = w.checkPlugin(tencentPlugin)
+	if err != nil {
+		return err
+	}
+	if os.Getenv("TENCENT_SECRET_ID") != "" && os.Getenv("TENCENT_SECRET_KEY") != "" {
+		return nil
+	}
+
+	path, err := w.credentialsPath(tencentCredentials)
+	if err != nil {
+		return err
+	}
+	creds, err := readCredentials(path, "default")
+	if err != nil {
+		return fmt.Errorf("tencent credentials aren't usable: %v", err)
+	}
+	for _, key := range []string{"tencent_appid", "tencent_secret_id", "tencent_secret_key"} {
+		if creds[key] == "" {
+			return fmt.Errorf("tencent credentials in %s are missing %s", path, key)
+		}
+	}
+	return nil
+}
+
+func (tencentProvider) ParseInfo(out string) *ServiceInfo {
+	return parseServiceInfo(out)
+}
+
+func (tencentProvider) ArtifactLimits() (int64, int64) {
+	return maxTencentZippedSize, maxTencentUnzippedSize
+}
+
+func (tencentProvider) Cleanup(ctx context.Context, w *Wrapper, removeErr error) error {
+	return removeErr
+}
@@ -0,0 +1,80 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// aliasedVersions are the versions aliases point at or route traffic to, which can't be deleted
+func (w *Wrapper) aliasedVersions(ctx context.Context, name string) (map[string]bool, error) {
+	var resp struct {
+		Aliases []aliasConfiguration
+	}
+	err := w.awsCmd(ctx, &resp, "lambda", "list-aliases", "--function-name", name)
+	if err != nil {
+		return nil, err
+	}
+
+	aliased := make(map[string]bool)
+	for _, a := range resp.Aliases {
+		aliased[a.FunctionVersion] = true
+		for v := range a.RoutingConfig.AdditionalVersionWeights {
+			aliased[v] = true
+		}
+	}
+	return aliased, nil
+}
+
+// PruneVersions deletes all but the last keep versions of every function in the stack, skipping aliased versions
+func (w *Wrapper) PruneVersions(ctx context.Context, keep int) error {
+	if keep < 1 {
+		return fmt.Errorf("must keep at least one version, got %d", keep)
+	}
+	err := w.requireAWS("pruning versions")
+	if err != nil {
+		return err
+	}
+
+	for _, key := range w.functionKeys() {
+		name := w.functionName(key)
+		versions, err := w.functionVersions(ctx, name)
+		if isAwsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if len(versions) <= keep {
+			continue
+		}
+		aliased, err := w.aliasedVersions(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		for _, v := range versions[:len(versions)-keep] {
+			version := strconv.Itoa(v)
+			if aliased[version] {
+				continue
+			}
+			err = w.awsCmd(ctx, nil, "lambda", "delete-function", "--function-name", name, "--qualifier", version)
+			if err != nil && !isAwsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pruneAfterDeploy doesn't fail the deploy that already succeeded
+func (w *Wrapper) pruneAfterDeploy(ctx context.Context) {
+	if w.KeepVersions == 0 {
+		return
+	}
+	err := w.PruneVersions(ctx, w.KeepVersions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to prune the versions of stack %s: %v\n", w.StackName(), err)
+	}
+}
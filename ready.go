@@ -0,0 +1,133 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const readyPollInterval = 2 * time.Second
+
+type functionConfiguration struct {
+	FunctionName     string
+	FunctionArn      string
+	Version          string
+	CodeSha256       string
+	LastModified     string
+	State            string
+	StateReason      string
+	LastUpdateStatus string
+	Runtime          string
+	MemorySize       int
+	Environment      struct {
+		Variables map[string]string
+	}
+	TracingConfig struct {
+		Mode string
+	}
+}
+
+// functionName is the deployed name of a function, the framework defaults to <service>-<stage>-<key>
+func (w *Wrapper) functionName(key string) string {
+	f, ok := w.stack.Functions[key]
+	if ok && f.Name != "" && !strings.Contains(f.Name, "${") {
+		return f.Name
+	}
+	return w.StackName() + "-" + key
+}
+
+func (w *Wrapper) functionKeys() []string {
+	keys := make([]string, 0, len(w.stack.Functions))
+	for key := range w.stack.Functions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (w *Wrapper) getFunctionConfiguration(ctx context.Context, name string) (*functionConfiguration, error) {
+	config := &functionConfiguration{}
+	err := w.awsCmd(ctx, config, "lambda", "get-function-configuration", "--function-name", name)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (w *Wrapper) provisionedConcurrencyReady(ctx context.Context, name string) (bool, error) {
+	var resp struct {
+		ProvisionedConcurrencyConfigs []struct {
+			Status       string
+			StatusReason string
+		}
+	}
+	err := w.awsCmd(ctx, &resp, "lambda", "list-provisioned-concurrency-configs", "--function-name", name)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range resp.ProvisionedConcurrencyConfigs {
+		if c.Status == "FAILED" {
+			return false, fmt.Errorf("provisioned concurrency of %s failed: %s", name, c.StatusReason)
+		}
+		if c.Status != "READY" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (w *Wrapper) functionReady(ctx context.Context, name string) (bool, error) {
+	config, err := w.getFunctionConfiguration(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	if config.State == "Failed" || config.LastUpdateStatus == "Failed" {
+		return false, fmt.Errorf("function %s failed to become active: %s", name, config.StateReason)
+	}
+	// functions deployed before lambda reported states have an empty State
+	if config.State != "" && config.State != "Active" {
+		return false, nil
+	}
+	if config.LastUpdateStatus != "" && config.LastUpdateStatus != "Successful" {
+		return false, nil
+	}
+	return w.provisionedConcurrencyReady(ctx, name)
+}
+
+// WaitReady polls every function of the stack until it's active and its provisioned concurrency is warm
+func (w *Wrapper) WaitReady(ctx context.Context, timeout time.Duration) error {
+	err := w.requireAWS("waiting for readiness")
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	pending := w.functionKeys()
+	for {
+		var notReady []string
+		for _, key := range pending {
+			ready, err := w.functionReady(ctx, w.functionName(key))
+			if ctx.Err() != nil {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if !ready {
+				notReady = append(notReady, key)
+			}
+		}
+		if ctx.Err() == nil && len(notReady) == 0 {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("functions not ready after %v: %s", timeout, strings.Join(pending, ", "))
+		}
+		pending = notReady
+
+		sleepContext(ctx, readyPollInterval)
+	}
+}
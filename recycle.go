@@ -0,0 +1,86 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type RecycleSample struct {
+	At time.Time
+	ColdStart
+}
+
+// RecycleWindow counts the cold starts of an hour of the observation
+type RecycleWindow struct {
+	From        time.Time
+	Invocations int
+	Cold        int
+}
+
+type RecycleResult struct {
+	Interval time.Duration
+	Samples  []RecycleSample
+	Windows  []RecycleWindow
+	// ColdRate is the share of the successful invocations that were cold starts
+	ColdRate float64
+}
+
+func recycleWindows(samples []RecycleSample, start time.Time) []RecycleWindow {
+	var windows []RecycleWindow
+	for _, s := range samples {
+		if s.Err != nil {
+			continue
+		}
+		i := int(s.At.Sub(start) / time.Hour)
+		for len(windows) <= i {
+			windows = append(windows, RecycleWindow{From: start.Add(time.Duration(len(windows)) * time.Hour)})
+		}
+		windows[i].Invocations++
+		if s.Cold {
+			windows[i].Cold++
+		}
+	}
+	return windows
+}
+
+// ObserveRecycling invokes the function every interval for the duration, e.g. every 10 minutes for 12 hours, to see
+// how often lambda recycles its idle instances, fn is passed each sample as it's taken and may be nil. Cancelling
+// ctx ends the observation early with the samples taken so far
+func (w *Wrapper) ObserveRecycling(ctx context.Context, key string, payload []byte, interval time.Duration, duration time.Duration, fn func(RecycleSample)) (*RecycleResult, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid interval %v", interval)
+	}
+
+	name := w.functionName(key)
+	result := &RecycleResult{Interval: interval}
+	start := time.Now()
+	for at := start; at.Before(start.Add(duration)); at = at.Add(interval) {
+		sleepContext(ctx, time.Until(at))
+		if ctx.Err() != nil {
+			break
+		}
+		sample := RecycleSample{At: time.Now(), ColdStart: w.timedInvoke(ctx, name, payload)}
+		if ctx.Err() != nil {
+			break
+		}
+		result.Samples = append(result.Samples, sample)
+		if fn != nil {
+			fn(sample)
+		}
+	}
+
+	result.Windows = recycleWindows(result.Samples, start)
+	ok, cold := 0, 0
+	for _, win := range result.Windows {
+		ok += win.Invocations
+		cold += win.Cold
+	}
+	if ok > 0 {
+		result.ColdRate = float64(cold) / float64(ok)
+	}
+	return result, nil
+}
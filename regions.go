@@ -0,0 +1,86 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type RegionDeployment struct {
+	Wrapper *Wrapper
+	Result  *DeployResult
+	Err     error
+}
+
+type RegionsError struct {
+	Errors map[string]error
+}
+
+func (e *RegionsError) Error() string {
+	regions := make([]string, 0, len(e.Errors))
+	for region := range e.Errors {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	lines := make([]string, 0, len(regions))
+	for _, region := range regions {
+		lines = append(lines, fmt.Sprintf("%s: %v", region, e.Errors[region]))
+	}
+	return "deploy failed in " + fmt.Sprint(len(regions)) + " regions:\n  " + strings.Join(lines, "\n  ")
+}
+
+// instanceKey names what a deployment keeps in the project dir and the locks it takes, the regions a suffix is
+// deployed to with ForRegion get one each
+func (w *Wrapper) instanceKey() string {
+	if region, ok := w.Opts["region"]; ok {
+		return w.suffix + "-" + region
+	}
+	return w.suffix
+}
+
+// ForRegion returns a wrapper deploying the same suffix to another region, stacks are regional so names don't collide,
+// its state, manifest and generated package config are kept apart from the other regions'
+func (w *Wrapper) ForRegion(region string) (*Wrapper, error) {
+	other, err := w.WithSuffix(w.suffix)
+	if err != nil {
+		return nil, err
+	}
+	other.Opts["region"] = region
+	return other, nil
+}
+
+// DeployToRegions deploys the service to every region concurrently, each region's wrapper is returned for later removal
+func (w *Wrapper) DeployToRegions(ctx context.Context, regions []string) (map[string]*RegionDeployment, error) {
+	deployments := make(map[string]*RegionDeployment, len(regions))
+	for _, region := range regions {
+		other, err := w.ForRegion(region)
+		if err != nil {
+			return nil, err
+		}
+		deployments[region] = &RegionDeployment{Wrapper: other}
+	}
+
+	var wg sync.WaitGroup
+	for _, d := range deployments {
+		wg.Add(1)
+		go func(d *RegionDeployment) {
+			defer wg.Done()
+			d.Result, d.Err = d.Wrapper.DeployStackContext(ctx)
+		}(d)
+	}
+	wg.Wait()
+
+	failures := make(map[string]error)
+	for region, d := range deployments {
+		if d.Err != nil {
+			failures[region] = d.Err
+		}
+	}
+	if len(failures) > 0 {
+		return deployments, &RegionsError{Errors: failures}
+	}
+	return deployments, nil
+}
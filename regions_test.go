@@ -0,0 +1,143 @@
+//go:build !windows
+// +build !windows
+
+package sls
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeSls deploys by printing service information, failing when the config it was given disappears mid-deploy
+const fakeSls = `#!/bin/sh
+cmd=$1
+config=serverless.yml
+region=us-east-1
+suffix=
+pkg=
+prev=
+for arg in "$@"; do
+	case "$prev" in
+	--config) config=$arg ;;
+	--region) region=$arg ;;
+	--suffix) suffix=$arg ;;
+	--package) pkg=$arg ;;
+	esac
+	prev=$arg
+done
+[ -f "$config" ] || { echo "config $config is missing" >&2; exit 1; }
+case "$cmd" in
+package)
+	mkdir -p "$pkg"
+	sleep 0.2
+	;;
+deploy)
+	sleep 0.3
+	[ -f "$config" ] || { echo "config $config was removed during the deploy" >&2; exit 1; }
+	printf 'Service Information\nservice: svc-%s\nstage: dev\nregion: %s\nstack: svc-%s-dev\nfunctions:\n  hello: svc-%s-dev-hello\n' "$suffix" "$region" "$suffix" "$suffix"
+	;;
+esac
+`
+
+// fakeAws answers every call with a deployed stack, the other commands ignore what they don't read
+const fakeAws = `#!/bin/sh
+echo '{"Stacks": [{"StackStatus": "CREATE_COMPLETE", "Outputs": [{"OutputKey": "ServerlessDeploymentBucketName", "OutputValue": "bucket"}]}]}'
+`
+
+const regionsConfig = `service: svc-${opt:suffix}
+provider:
+  name: aws
+  runtime: nodejs14.x
+functions:
+  hello:
+    handler: handler.hello
+    package:
+      artifact: hello.zip
+`
+
+func writeExecutable(t *testing.T, p string, content string) {
+	err := ioutil.WriteFile(p, []byte(content), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeployToRegionsSharesDir(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "sls-regions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	bin := filepath.Join(tmp, "bin")
+	dir := filepath.Join(tmp, "service")
+	for _, d := range []string{bin, filepath.Join(dir, "build")} {
+		err = os.MkdirAll(d, 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeExecutable(t, filepath.Join(bin, "sls"), fakeSls)
+	writeExecutable(t, filepath.Join(bin, "aws"), fakeAws)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	err = ioutil.WriteFile(filepath.Join(dir, YamlName), []byte(regionsConfig), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "build", "hello.zip"), []byte("zip"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWithSuffix("aws", dir, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the moved package references make every region deploy with a generated config
+	w.ArtifactsDir = "build"
+	w.SkipUnchanged = true
+
+	deployments, err := w.DeployToRegions(context.Background(), []string{"us-east-1", "eu-west-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for region, d := range deployments {
+		if d.Result.Region != region {
+			t.Errorf("deploy to %s reported region %s", region, d.Result.Region)
+		}
+		if !fileExists(d.Wrapper.deployStatePath()) {
+			t.Errorf("deploy to %s saved no state at %s", region, d.Wrapper.deployStatePath())
+		}
+	}
+	if deployments["us-east-1"].Wrapper.deployStatePath() == deployments["eu-west-1"].Wrapper.deployStatePath() {
+		t.Error("regions share a deploy state file")
+	}
+
+	records, err := w.registry().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("registry has %d records, want one per region: %+v", len(records), records)
+	}
+
+	err = deployments["us-east-1"].Wrapper.RemoveStackContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err = w.registry().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Region != "eu-west-1" {
+		t.Errorf("removing one region left the records %+v", records)
+	}
+	if !fileExists(deployments["eu-west-1"].Wrapper.deployStatePath()) {
+		t.Error("removing one region removed the other's deploy state")
+	}
+}
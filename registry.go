@@ -0,0 +1,219 @@
+package sls
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type DeploymentRecord struct {
+	Service    string    `json:"service"`
+	Suffix     string    `json:"suffix"`
+	Stage      string    `json:"stage"`
+	Region     string    `json:"region"`
+	StackName  string    `json:"stackName"`
+	Dir        string    `json:"dir"`
+	GitSHA     string    `json:"gitSha,omitempty"`
+	DeployedAt time.Time `json:"deployedAt"`
+	// Artifacts maps the deployed artifacts' file names to their sha256
+	Artifacts    map[string]string `json:"artifacts,omitempty"`
+	PromotedFrom string            `json:"promotedFrom,omitempty"`
+}
+
+// Registry keeps track of deployed stacks across processes, a record is identified by its stack name and region
+type Registry interface {
+	Record(rec DeploymentRecord) error
+	Forget(stackName string, region string) error
+	List() ([]DeploymentRecord, error)
+}
+
+// FileRegistry keeps the records in a json file, changes hold a lock on the file so concurrent processes don't lose
+// each other's records
+type FileRegistry struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// lock serializes the read-modify-write of the records within the process and across processes
+func (r *FileRegistry) lock() (func(), error) {
+	r.mu.Lock()
+	locker := &FileLocker{Dir: filepath.Dir(r.Path)}
+	unlock, err := locker.Lock(context.Background(), filepath.Base(r.Path))
+	if err != nil {
+		r.mu.Unlock()
+		return nil, err
+	}
+	return func() {
+		unlock()
+		r.mu.Unlock()
+	}, nil
+}
+
+func (r *FileRegistry) load() ([]DeploymentRecord, error) {
+	data, err := ioutil.ReadFile(r.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []DeploymentRecord
+	err = json.Unmarshal(data, &records)
+	return records, err
+}
+
+func (r *FileRegistry) save(records []DeploymentRecord) error {
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write and rename so a crash never leaves a truncated registry behind
+	tmp, err := ioutil.TempFile(filepath.Dir(r.Path), filepath.Base(r.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.Path)
+}
+
+func (r *FileRegistry) Record(rec DeploymentRecord) error {
+	unlock, err := r.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	records, err := r.load()
+	if err != nil {
+		return err
+	}
+	records = append(removeRecord(records, rec.StackName, rec.Region), rec)
+	return r.save(records)
+}
+
+func (r *FileRegistry) Forget(stackName string, region string) error {
+	unlock, err := r.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	records, err := r.load()
+	if err != nil {
+		return err
+	}
+	return r.save(removeRecord(records, stackName, region))
+}
+
+func (r *FileRegistry) List() ([]DeploymentRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.load()
+}
+
+func removeRecord(records []DeploymentRecord, stackName string, region string) []DeploymentRecord {
+	var kept []DeploymentRecord
+	for _, rec := range records {
+		if rec.StackName != stackName || rec.Region != region {
+			kept = append(kept, rec)
+		}
+	}
+	return kept
+}
+
+var fileRegistries = struct {
+	sync.Mutex
+	registries map[string]*FileRegistry
+}{registries: make(map[string]*FileRegistry)}
+
+// registry shares one FileRegistry per path between the wrappers of the process
+func (w *Wrapper) registry() Registry {
+	if w.Registry != nil {
+		return w.Registry
+	}
+	path, err := filepath.Abs(filepath.Join(w.stateDir(), "deployments.json"))
+	if err != nil {
+		path = filepath.Join(w.stateDir(), "deployments.json")
+	}
+
+	fileRegistries.Lock()
+	defer fileRegistries.Unlock()
+	r, ok := fileRegistries.registries[path]
+	if !ok {
+		r = &FileRegistry{Path: path}
+		fileRegistries.registries[path] = r
+	}
+	return r
+}
+
+func (w *Wrapper) gitSHA() string {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = w.yamlDirPath
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (w *Wrapper) recordDeployment(result *DeployResult) error {
+	dir, err := filepath.Abs(w.yamlDirPath)
+	if err != nil {
+		return err
+	}
+	var artifacts map[string]string
+	for _, b := range result.Builds {
+		if b.Artifact != "" && b.Sha256 != "" {
+			if artifacts == nil {
+				artifacts = make(map[string]string)
+			}
+			artifacts[filepath.Base(b.Artifact)] = b.Sha256
+		}
+	}
+	// records are forgotten by the wrapper's region, which the parsed info lacks when the output couldn't be read
+	region := result.Region
+	if region == "" {
+		region = w.Region()
+	}
+	return w.registry().Record(DeploymentRecord{
+		Service:      w.StackId(),
+		Suffix:       w.suffix,
+		Stage:        result.Stage,
+		Region:       region,
+		StackName:    result.StackName,
+		Dir:          dir,
+		GitSHA:       w.gitSHA(),
+		DeployedAt:   time.Now().UTC(),
+		Artifacts:    artifacts,
+		PromotedFrom: result.PromotedFrom,
+	})
+}
+
+// Deployments lists the registered deployments of this service
+func (w *Wrapper) Deployments() ([]DeploymentRecord, error) {
+	records, err := w.registry().List()
+	if err != nil {
+		return nil, err
+	}
+	var own []DeploymentRecord
+	for _, rec := range records {
+		if rec.Service == w.StackId() {
+			own = append(own, rec)
+		}
+	}
+	return own, nil
+}
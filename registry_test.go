@@ -0,0 +1,54 @@
+package sls
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileRegistryConcurrentRecords(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sls-registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "deployments.json")
+	defer func(interval time.Duration) { lockPollInterval = interval }(lockPollInterval)
+	lockPollInterval = 10 * time.Millisecond
+
+	// separate registries on the same path stand in for separate processes
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r := &FileRegistry{Path: path}
+			errs <- r.Record(DeploymentRecord{StackName: fmt.Sprintf("svc-%d-dev", i), Region: "us-east-1"})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := (&FileRegistry{Path: path}).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != n {
+		t.Fatalf("expected %d records, found %d", n, len(records))
+	}
+	tmps, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	if len(tmps) != 0 {
+		t.Errorf("temporary files left behind: %v", tmps)
+	}
+}
@@ -0,0 +1,103 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+const deploymentBucketId = "ServerlessDeploymentBucket"
+
+type stackResource struct {
+	LogicalResourceId    string
+	PhysicalResourceId   string
+	ResourceType         string
+	ResourceStatus       string
+	ResourceStatusReason string
+}
+
+func (w *Wrapper) stackResources(ctx context.Context) ([]stackResource, error) {
+	var resp struct {
+		StackResources []stackResource
+	}
+	err := w.awsCmd(ctx, &resp, "cloudformation", "describe-stack-resources", "--stack-name", w.StackName())
+	if err != nil {
+		return nil, err
+	}
+	return resp.StackResources, nil
+}
+
+func (w *Wrapper) emptyBucket(ctx context.Context, bucket string) error {
+	err := w.awsCmd(ctx, nil, "s3", "rm", "s3://"+bucket, "--recursive")
+	if isAwsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (w *Wrapper) deleteStack(ctx context.Context, retain []string) error {
+	args := []string{"cloudformation", "delete-stack", "--stack-name", w.StackName()}
+	if len(retain) > 0 {
+		args = append(args, "--retain-resources")
+		args = append(args, retain...)
+	}
+	err := w.awsCmd(ctx, nil, args...)
+	if err != nil {
+		return err
+	}
+	return w.waitStackDeleted(ctx)
+}
+
+// forceRemove handles stacks the framework couldn't remove, it empties the buckets, retries the delete and finally
+// retains whatever still fails to delete so the stack itself goes away
+func (w *Wrapper) forceRemove(ctx context.Context, cause error) error {
+	err := w.requireAWS("forced removal")
+	if err != nil {
+		return err
+	}
+	exists, err := w.StackExists(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	resources, err := w.stackResources(ctx)
+	if err != nil {
+		return err
+	}
+	for _, r := range resources {
+		if r.ResourceType != "AWS::S3::Bucket" || r.PhysicalResourceId == "" {
+			continue
+		}
+		if r.LogicalResourceId != deploymentBucketId && r.ResourceStatus != "DELETE_FAILED" {
+			continue
+		}
+		err = w.emptyBucket(ctx, r.PhysicalResourceId)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = w.deleteStack(ctx, nil)
+	if _, failed := err.(*deleteFailedError); !failed {
+		return err
+	}
+
+	resources, err = w.stackResources(ctx)
+	if err != nil {
+		return err
+	}
+	var retain []string
+	for _, r := range resources {
+		if r.ResourceStatus == "DELETE_FAILED" {
+			retain = append(retain, r.LogicalResourceId)
+		}
+	}
+	if len(retain) == 0 {
+		return fmt.Errorf("stack %s can't be removed: %v", w.StackName(), cause)
+	}
+	fmt.Fprintf(os.Stderr, "retaining resources that failed to delete: %v\n", retain)
+	return w.deleteStack(ctx, retain)
+}
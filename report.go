@@ -0,0 +1,108 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report is what lambda logs in the REPORT line at the end of each invocation
+type Report struct {
+	RequestID      string
+	Duration       time.Duration
+	BilledDuration time.Duration
+	MemorySize     int
+	MaxMemoryUsed  int
+	// InitDuration is only set on cold starts
+	InitDuration time.Duration
+	// TraceID is set when the function is traced with x-ray
+	TraceID string
+}
+
+// parseReport reads a line such as "REPORT RequestId: id\tDuration: 2.36 ms\tBilled Duration: 3 ms\t..."
+func parseReport(line string) (*Report, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "REPORT RequestId: ") {
+		return nil, false
+	}
+	r := &Report{}
+	for _, field := range strings.Split(line, "\t") {
+		key, value, ok := splitKeyValue(strings.TrimPrefix(field, "REPORT "))
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		n, _ := strconv.ParseFloat(fields[0], 64)
+		ms := time.Duration(n * float64(time.Millisecond))
+		switch key {
+		case "RequestId":
+			r.RequestID = fields[0]
+		case "Duration":
+			r.Duration = ms
+		case "Billed Duration":
+			r.BilledDuration = ms
+		case "Memory Size":
+			r.MemorySize = int(n)
+		case "Max Memory Used":
+			r.MaxMemoryUsed = int(n)
+		case "Init Duration":
+			r.InitDuration = ms
+		case "XRAY TraceId":
+			r.TraceID = fields[0]
+		}
+	}
+	return r, true
+}
+
+// parseReports reads the REPORT lines of logs by request id
+func parseReports(logs string) map[string]Report {
+	reports := make(map[string]Report)
+	last := ""
+	for _, line := range strings.Split(logs, "\n") {
+		if r, ok := parseReport(line); ok {
+			reports[r.RequestID] = *r
+			last = r.RequestID
+			continue
+		}
+		// traced functions' REPORT lines continue on a line of their own
+		if r, ok := parseReport("REPORT RequestId: " + last + "\t" + strings.TrimSpace(line)); ok && last != "" && r.TraceID != "" {
+			report := reports[last]
+			report.TraceID = r.TraceID
+			reports[last] = report
+		}
+		last = ""
+	}
+	return reports
+}
+
+// reportFromLogs returns the REPORT of a log tail, which holds a single invocation
+func reportFromLogs(logs string) *Report {
+	for _, r := range parseReports(logs) {
+		r := r
+		return &r
+	}
+	return nil
+}
+
+// Reports fetches the REPORT lines the function logged since the given time by request id
+func (w *Wrapper) Reports(ctx context.Context, key string, since time.Time) (map[string]Report, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	events, err := w.filterLogEvents(ctx, w.functionName(key), since, "--filter-pattern", `"REPORT RequestId"`)
+	if err != nil {
+		return nil, err
+	}
+	reports := make(map[string]Report)
+	for _, e := range events {
+		if r, ok := parseReport(e.Message); ok {
+			reports[r.RequestID] = *r
+		}
+	}
+	return reports, nil
+}
@@ -0,0 +1,39 @@
+package sls
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// zip timestamps can't go before 1980
+const minZipEpoch = 315532800
+
+func (w *Wrapper) sourceDateEpoch() string {
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		return epoch
+	}
+
+	cmd := exec.Command("git", "log", "-1", "--format=%ct")
+	cmd.Dir = w.yamlDirPath
+	out, err := cmd.Output()
+	if err == nil {
+		if epoch := strings.TrimSpace(string(out)); epoch != "" {
+			return epoch
+		}
+	}
+	return strconv.Itoa(minZipEpoch)
+}
+
+func (w *Wrapper) artifactModTime() time.Time {
+	if !w.Reproducible {
+		return time.Time{}
+	}
+	epoch, err := strconv.ParseInt(w.sourceDateEpoch(), 10, 64)
+	if err != nil || epoch < minZipEpoch {
+		epoch = minZipEpoch
+	}
+	return time.Unix(epoch, 0).UTC()
+}
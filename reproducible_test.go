@@ -0,0 +1,83 @@
+package sls
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReproducibleZip(t *testing.T) {
+	defer os.Setenv("SOURCE_DATE_EPOCH", os.Getenv("SOURCE_DATE_EPOCH"))
+	os.Setenv("SOURCE_DATE_EPOCH", "1614852902")
+	w := &Wrapper{Reproducible: true}
+
+	tmp, err := ioutil.TempDir("", "sls-reproducible")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	// the same sources checked out at different times
+	var zips [][]byte
+	for i, mtime := range []time.Time{time.Now(), time.Now().Add(-48 * time.Hour)} {
+		src := filepath.Join(tmp, "src", string('a'+rune(i)))
+		for _, name := range []string{"main.js", "lib/util.js"} {
+			p := filepath.Join(src, filepath.FromSlash(name))
+			err = os.MkdirAll(filepath.Dir(p), 0755)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = ioutil.WriteFile(p, []byte(name), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = os.Chtimes(p, mtime, mtime)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		files, err := dirArtifactFiles(src, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the listing order mustn't matter either
+		if i == 1 {
+			files[0], files[1] = files[1], files[0]
+		}
+		artifact := filepath.Join(tmp, "deploy.zip")
+		err = zipArtifact(artifact, files, w.artifactModTime())
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadFile(artifact)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zips = append(zips, data)
+	}
+	if !bytes.Equal(zips[0], zips[1]) {
+		t.Error("zips of the same sources differ")
+	}
+}
+
+func TestArtifactModTime(t *testing.T) {
+	defer os.Setenv("SOURCE_DATE_EPOCH", os.Getenv("SOURCE_DATE_EPOCH"))
+
+	if got := (&Wrapper{}).artifactModTime(); !got.IsZero() {
+		t.Errorf("expected the files' own times without Reproducible, got %v", got)
+	}
+
+	w := &Wrapper{Reproducible: true}
+	os.Setenv("SOURCE_DATE_EPOCH", "1614852902")
+	if got := w.artifactModTime(); !got.Equal(time.Unix(1614852902, 0)) {
+		t.Errorf("expected SOURCE_DATE_EPOCH, got %v", got)
+	}
+	// zip timestamps start at 1980
+	os.Setenv("SOURCE_DATE_EPOCH", "0")
+	if got := w.artifactModTime(); !got.Equal(time.Unix(minZipEpoch, 0)) {
+		t.Errorf("expected the epoch clamped to 1980, got %v", got)
+	}
+}
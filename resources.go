@@ -0,0 +1,103 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const maxStackResources = 500
+
+// ResourceCount breaks the packaged template's resources down by the function they belong to, by the framework's
+// naming, the rest are Shared
+type ResourceCount struct {
+	Total     int
+	Functions map[string]int
+	Shared    int
+	ByType    map[string]int
+}
+
+func (w *Wrapper) countResources(t *template) *ResourceCount {
+	// longest ids first so a function named like another's prefix gets its own resources
+	prefixes := make(map[string]string)
+	var ids []string
+	for key := range w.stack.Functions {
+		id := functionLogicalId(key)
+		prefixes[id] = key
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return len(ids[i]) > len(ids[j]) })
+
+	count := &ResourceCount{Total: len(t.Resources), Functions: make(map[string]int), ByType: make(map[string]int)}
+	for id, r := range t.Resources {
+		count.ByType[r.Type]++
+		owner := ""
+		for _, prefix := range ids {
+			if strings.HasPrefix(id, prefix) {
+				owner = prefixes[prefix]
+				break
+			}
+		}
+		if owner == "" {
+			count.Shared++
+			continue
+		}
+		count.Functions[owner]++
+	}
+	return count
+}
+
+// breakdown lists the functions with the most resources first, so it's clear which ones to split off
+func (c *ResourceCount) breakdown() string {
+	keys := make([]string, 0, len(c.Functions))
+	for key := range c.Functions {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if c.Functions[keys[i]] != c.Functions[keys[j]] {
+			return c.Functions[keys[i]] > c.Functions[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	parts := make([]string, 0, len(keys)+1)
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s %d", key, c.Functions[key]))
+	}
+	parts = append(parts, fmt.Sprintf("shared %d", c.Shared))
+	return strings.Join(parts, ", ")
+}
+
+func (c *ResourceCount) check(stackName string) error {
+	if c.Total > maxStackResources {
+		return fmt.Errorf("stack %s has %d resources (%s), exceeding cloudformation's limit of %d", stackName, c.Total, c.breakdown(), maxStackResources)
+	}
+	if float64(c.Total) > maxStackResources*sizeWarnRatio {
+		fmt.Fprintf(os.Stderr, "warning: stack %s has %d resources (%s), close to cloudformation's limit of %d\n", stackName, c.Total, c.breakdown(), maxStackResources)
+	}
+	return nil
+}
+
+// CountResources packages the stack and counts the resources its template creates
+func (w *Wrapper) CountResources(ctx context.Context) (*ResourceCount, error) {
+	packageDir, err := ioutil.TempDir("", "sls-resources")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(packageDir)
+
+	_, err = w.Package(ctx, packageDir)
+	if err != nil {
+		return nil, err
+	}
+	t, err := readTemplate(filepath.Join(packageDir, packagedTemplateName))
+	if err != nil {
+		return nil, err
+	}
+	count := w.countResources(t)
+	return count, count.check(w.StackName())
+}
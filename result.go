@@ -0,0 +1,83 @@
+package sls
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+type BuildResult struct {
+	Runtime   string        `json:"runtime"`
+	Functions []string      `json:"functions,omitempty"`
+	Artifact  string        `json:"artifact,omitempty"`
+	Image     string        `json:"image,omitempty"`
+	Size      int64         `json:"size"`
+	Sha256    string        `json:"sha256,omitempty"`
+	Duration  time.Duration `json:"duration"`
+}
+
+func (w *Wrapper) buildResults(log *buildLog, duration time.Duration) ([]BuildResult, error) {
+	if len(log.artifacts) == 0 {
+		return []BuildResult{{Runtime: log.runtime, Duration: duration}}, nil
+	}
+
+	var results []BuildResult
+	for _, a := range log.artifacts {
+		if a.image != "" {
+			results = append(results, BuildResult{
+				Runtime:   log.runtime,
+				Functions: a.functions,
+				Image:     a.image,
+				Sha256:    imageDigest(a.image),
+				Duration:  duration,
+			})
+			continue
+		}
+		size, sum, err := fileChecksum(a.path)
+		if err != nil {
+			return nil, err
+		}
+		functions := a.functions
+		if len(functions) == 0 {
+			functions = w.artifactFunctions(a.path)
+		}
+		results = append(results, BuildResult{
+			Runtime:   log.runtime,
+			Functions: functions,
+			Artifact:  a.path,
+			Size:      size,
+			Sha256:    sum,
+			Duration:  duration,
+		})
+	}
+	return results, nil
+}
+
+func (w *Wrapper) artifactFunctions(artifact string) []string {
+	var functions []string
+	for key, f := range w.stack.Functions {
+		if f.Package.Artifact != "" && w.artifactRef(f.Package.Artifact) == artifact {
+			functions = append(functions, key)
+		}
+	}
+	sort.Strings(functions)
+	return functions
+}
+
+func fileChecksum(p string) (int64, string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,157 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Deployment struct {
+	Timestamp string
+	Datetime  time.Time
+	Files     []string
+}
+
+func (w *Wrapper) ListDeployments(ctx context.Context) ([]Deployment, error) {
+	out, err := w.execSlsCmd(ctx, w.yamlDirPath, "deploy", "list")
+	if err != nil {
+		return nil, err
+	}
+	return parseDeployments(out), nil
+}
+
+func parseDeployments(out string) []Deployment {
+	var deployments []Deployment
+	var cur *Deployment
+	for _, line := range slsLines(out) {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Timestamp:"):
+			deployments = append(deployments, Deployment{Timestamp: strings.TrimSpace(strings.TrimPrefix(line, "Timestamp:"))})
+			cur = &deployments[len(deployments)-1]
+		case cur == nil:
+		case strings.HasPrefix(line, "Datetime:"):
+			cur.Datetime, _ = time.Parse(time.RFC3339, strings.TrimSpace(strings.TrimPrefix(line, "Datetime:")))
+		case strings.HasPrefix(line, "- "):
+			cur.Files = append(cur.Files, strings.TrimPrefix(line, "- "))
+		}
+	}
+	return deployments
+}
+
+// FunctionVersions are a deployed function's last versions, as listed by the framework
+type FunctionVersions struct {
+	Name     string
+	Versions []string
+}
+
+func (w *Wrapper) ListDeployedFunctions(ctx context.Context) ([]FunctionVersions, error) {
+	out, err := w.execSlsCmd(ctx, w.yamlDirPath, "deploy", "list", "functions")
+	if err != nil {
+		return nil, err
+	}
+	return parseFunctionVersions(out), nil
+}
+
+// parseFunctionVersions reads "<name>: 1, 2, 3" lines, skipping the headers around them
+func parseFunctionVersions(out string) []FunctionVersions {
+	var functions []FunctionVersions
+	for _, line := range slsLines(out) {
+		name, value, ok := splitKeyValue(strings.TrimSpace(line))
+		if !ok || name == "" || value == "" || strings.Contains(name, " ") {
+			continue
+		}
+		f := FunctionVersions{Name: name}
+		for _, v := range strings.Split(value, ",") {
+			v = strings.TrimSpace(v)
+			if _, err := strconv.Atoi(v); err != nil && v != "$LATEST" {
+				f.Versions = nil
+				break
+			}
+			f.Versions = append(f.Versions, v)
+		}
+		if len(f.Versions) > 0 {
+			functions = append(functions, f)
+		}
+	}
+	return functions
+}
+
+// Rollback redeploys the stack to a timestamp returned by ListDeployments
+func (w *Wrapper) Rollback(ctx context.Context, timestamp string) error {
+	unlock, err := w.lockStack(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	_, err = w.execSlsCmd(ctx, w.yamlDirPath, "rollback", "-t", timestamp)
+	w.cacheInfo(nil)
+	return err
+}
+
+// RollbackFunction points a single function back to one of its published versions
+func (w *Wrapper) RollbackFunction(ctx context.Context, name string, version string) error {
+	if _, ok := w.stack.Functions[name]; !ok {
+		return fmt.Errorf("function %s is not defined in %s", name, w.configName)
+	}
+	unlock, err := w.lockStack(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	_, err = w.execSlsCmd(ctx, w.yamlDirPath, "rollback", "function", "-f", name, "--function-version", version)
+	w.cacheInfo(nil)
+	return err
+}
+
+const lambdaTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// DeployedFunction is the latest published version of a function in the stack, or $LATEST when none was published
+type DeployedFunction struct {
+	Key          string
+	Name         string
+	Version      string
+	LastModified time.Time
+}
+
+func (w *Wrapper) ListFunctions(ctx context.Context) ([]DeployedFunction, error) {
+	info, err := w.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var functions []DeployedFunction
+	for _, key := range w.functionKeys() {
+		name, ok := info.Functions[key]
+		if !ok {
+			name = w.functionName(key)
+		}
+		versions, err := w.listVersionsByFunction(ctx, name)
+		if isAwsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		f := DeployedFunction{Key: key, Name: name}
+		latest := -1
+		for _, v := range versions {
+			n, err := strconv.Atoi(v.Version)
+			if err != nil {
+				n = 0
+			}
+			if n > latest {
+				latest = n
+				f.Version = v.Version
+				f.LastModified, _ = time.Parse(lambdaTimeLayout, v.LastModified)
+			}
+		}
+		functions = append(functions, f)
+	}
+	return functions, nil
+}
@@ -0,0 +1,373 @@
+package sls
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	sampleAccountId = "123456789012"
+	sampleId        = "c80e8021-a70a-42c7-a470-796cb5c2d5a2"
+)
+
+func sampleTime() string {
+	return time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// APIGatewayProxyEvent is a REST API lambda proxy event, path is the resource path as declared in the config
+func APIGatewayProxyEvent(method string, path string, body []byte) []byte {
+	path = "/" + strings.TrimPrefix(path, "/")
+	headers := map[string]string{"Content-Type": "application/json"}
+	event := map[string]interface{}{
+		"resource":              path,
+		"path":                  path,
+		"httpMethod":            strings.ToUpper(method),
+		"headers":               headers,
+		"multiValueHeaders":     map[string][]string{"Content-Type": {"application/json"}},
+		"queryStringParameters": nil,
+		"pathParameters":        nil,
+		"stageVariables":        nil,
+		"requestContext": map[string]interface{}{
+			"accountId":    sampleAccountId,
+			"resourcePath": path,
+			"httpMethod":   strings.ToUpper(method),
+			"requestId":    sampleId,
+			"identity":     map[string]interface{}{"sourceIp": "127.0.0.1"},
+		},
+		"body":            string(body),
+		"isBase64Encoded": false,
+	}
+	data, _ := json.Marshal(event)
+	return data
+}
+
+// HTTPAPIEvent is an HTTP API event in the 2.0 payload format
+func HTTPAPIEvent(method string, path string, body []byte) []byte {
+	path = "/" + strings.TrimPrefix(path, "/")
+	event := map[string]interface{}{
+		"version":        "2.0",
+		"routeKey":       strings.ToUpper(method) + " " + path,
+		"rawPath":        path,
+		"rawQueryString": "",
+		"headers":        map[string]string{"content-type": "application/json"},
+		"requestContext": map[string]interface{}{
+			"accountId": sampleAccountId,
+			"requestId": sampleId,
+			"http": map[string]interface{}{
+				"method":   strings.ToUpper(method),
+				"path":     path,
+				"protocol": "HTTP/1.1",
+				"sourceIp": "127.0.0.1",
+			},
+		},
+		"body":            string(body),
+		"isBase64Encoded": false,
+	}
+	data, _ := json.Marshal(event)
+	return data
+}
+
+func SQSEvent(queueArn string, region string, bodies ...string) []byte {
+	var records []interface{}
+	for _, body := range bodies {
+		sum := md5.Sum([]byte(body))
+		records = append(records, map[string]interface{}{
+			"messageId":     sampleId,
+			"receiptHandle": "AQEBwJnKyrHigUMZj6rYigCgxlaS3SLy0a",
+			"body":          body,
+			"attributes": map[string]string{
+				"ApproximateReceiveCount":          "1",
+				"SentTimestamp":                    fmt.Sprint(time.Now().UnixNano() / int64(time.Millisecond)),
+				"SenderId":                         sampleAccountId,
+				"ApproximateFirstReceiveTimestamp": fmt.Sprint(time.Now().UnixNano() / int64(time.Millisecond)),
+			},
+			"messageAttributes": map[string]interface{}{},
+			"md5OfBody":         hex.EncodeToString(sum[:]),
+			"eventSource":       "aws:sqs",
+			"eventSourceARN":    queueArn,
+			"awsRegion":         region,
+		})
+	}
+	data, _ := json.Marshal(map[string]interface{}{"Records": records})
+	return data
+}
+
+// S3Event is a bucket notification, eventName is e.g. ObjectCreated:Put
+func S3Event(bucket string, key string, eventName string, region string) []byte {
+	record := map[string]interface{}{
+		"eventVersion": "2.1",
+		"eventSource":  "aws:s3",
+		"awsRegion":    region,
+		"eventTime":    sampleTime(),
+		"eventName":    eventName,
+		"s3": map[string]interface{}{
+			"s3SchemaVersion": "1.0",
+			"configurationId": sampleId,
+			"bucket": map[string]interface{}{
+				"name": bucket,
+				"arn":  "arn:aws:s3:::" + bucket,
+			},
+			"object": map[string]interface{}{
+				"key":  key,
+				"size": 1024,
+			},
+		},
+	}
+	data, _ := json.Marshal(map[string]interface{}{"Records": []interface{}{record}})
+	return data
+}
+
+func SNSEvent(topicArn string, message string) []byte {
+	record := map[string]interface{}{
+		"EventSource":          "aws:sns",
+		"EventVersion":         "1.0",
+		"EventSubscriptionArn": topicArn + ":" + sampleId,
+		"Sns": map[string]interface{}{
+			"Type":              "Notification",
+			"MessageId":         sampleId,
+			"TopicArn":          topicArn,
+			"Subject":           nil,
+			"Message":           message,
+			"Timestamp":         sampleTime(),
+			"MessageAttributes": map[string]interface{}{},
+		},
+	}
+	data, _ := json.Marshal(map[string]interface{}{"Records": []interface{}{record}})
+	return data
+}
+
+// KinesisEvent has a record per data blob, which lambda delivers base64 encoded
+func KinesisEvent(streamArn string, region string, data ...[]byte) []byte {
+	var records []interface{}
+	for i, d := range data {
+		sequence := fmt.Sprintf("49590338271490256608559692538361571095921575989136588%03d", i)
+		records = append(records, map[string]interface{}{
+			"kinesis": map[string]interface{}{
+				"kinesisSchemaVersion":        "1.0",
+				"partitionKey":                fmt.Sprint(i),
+				"sequenceNumber":              sequence,
+				"data":                        base64.StdEncoding.EncodeToString(d),
+				"approximateArrivalTimestamp": float64(time.Now().UnixNano()/int64(time.Millisecond)) / 1000,
+			},
+			"eventSource":       "aws:kinesis",
+			"eventVersion":      "1.0",
+			"eventID":           "shardId-000000000000:" + sequence,
+			"eventName":         "aws:kinesis:record",
+			"invokeIdentityArn": fmt.Sprintf("arn:aws:iam::%s:role/lambda-role", sampleAccountId),
+			"awsRegion":         region,
+			"eventSourceARN":    streamArn,
+		})
+	}
+	event, _ := json.Marshal(map[string]interface{}{"Records": records})
+	return event
+}
+
+// DynamoDBStreamEvent is an INSERT of an item whose Id is a sample id and whose Body attribute holds body
+func DynamoDBStreamEvent(streamArn string, region string, body string) []byte {
+	keys := map[string]interface{}{"Id": map[string]string{"S": sampleId}}
+	record := map[string]interface{}{
+		"eventID":      "1",
+		"eventName":    "INSERT",
+		"eventVersion": "1.1",
+		"eventSource":  "aws:dynamodb",
+		"awsRegion":    region,
+		"dynamodb": map[string]interface{}{
+			"ApproximateCreationDateTime": time.Now().Unix(),
+			"Keys":                        keys,
+			"NewImage":                    map[string]interface{}{"Id": keys["Id"], "Body": map[string]string{"S": body}},
+			"SequenceNumber":              "111",
+			"SizeBytes":                   len(body) + len(sampleId),
+			"StreamViewType":              "NEW_AND_OLD_IMAGES",
+		},
+		"eventSourceARN": streamArn,
+	}
+	data, _ := json.Marshal(map[string]interface{}{"Records": []interface{}{record}})
+	return data
+}
+
+// EventBridgeEvent is an event as delivered by a rule, detail is used as is when it's json
+func EventBridgeEvent(source string, detailType string, detail []byte, region string) []byte {
+	raw := json.RawMessage("{}")
+	if len(detail) > 0 && json.Valid(detail) {
+		raw = json.RawMessage(detail)
+	}
+	event := map[string]interface{}{
+		"version":     "0",
+		"id":          sampleId,
+		"detail-type": detailType,
+		"source":      source,
+		"account":     sampleAccountId,
+		"time":        time.Now().UTC().Format(time.RFC3339),
+		"region":      region,
+		"resources":   []string{},
+		"detail":      raw,
+	}
+	data, _ := json.Marshal(event)
+	return data
+}
+
+// ScheduleEvent is what a schedule event without an input delivers
+func ScheduleEvent(ruleArn string, region string) []byte {
+	event := map[string]interface{}{
+		"version":     "0",
+		"id":          sampleId,
+		"detail-type": "Scheduled Event",
+		"source":      "aws.events",
+		"account":     sampleAccountId,
+		"time":        time.Now().UTC().Format(time.RFC3339),
+		"region":      region,
+		"resources":   []string{ruleArn},
+		"detail":      map[string]interface{}{},
+	}
+	data, _ := json.Marshal(event)
+	return data
+}
+
+// eventField reads a field of an event declared as a map, arns given as intrinsic functions can't be resolved
+func eventField(v interface{}, name string) string {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return ""
+	}
+	s, _ := m[name].(string)
+	return s
+}
+
+// eventFirst reads the first value of a list field of a map, such as an event pattern's source
+func eventFirst(v interface{}, name string) string {
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return ""
+	}
+	list, _ := m[name].([]interface{})
+	if len(list) == 0 {
+		return ""
+	}
+	s, _ := list[0].(string)
+	return s
+}
+
+// EventFixture is a sample payload for one of a function's events, Kind is the event's key in the config
+type EventFixture struct {
+	Kind    string
+	Payload []byte
+}
+
+// sampleEvent generates the payload of one event, body is the request body, message or record data
+func (w *Wrapper) sampleEvent(key string, kind string, v interface{}, body []byte) ([]byte, bool) {
+	region := w.Region()
+	switch kind {
+	case "http", "httpApi":
+		for _, h := range httpEvents(FunctionMeta{Events: []map[string]interface{}{{kind: v}}}) {
+			method := h.method
+			if method == "ANY" || method == "*" {
+				method = "GET"
+			}
+			if kind == "http" {
+				return APIGatewayProxyEvent(method, h.path, body), true
+			}
+			return HTTPAPIEvent(method, h.path, body), true
+		}
+	case "sqs":
+		arn, _ := v.(string)
+		if arn == "" {
+			arn = eventField(v, "arn")
+		}
+		if !strings.HasPrefix(arn, "arn:") {
+			arn = fmt.Sprintf("arn:aws:sqs:%s:%s:%s-queue", region, sampleAccountId, key)
+		}
+		return SQSEvent(arn, region, string(body)), true
+	case "stream":
+		arn, _ := v.(string)
+		streamType := ""
+		if arn == "" {
+			arn = eventField(v, "arn")
+			streamType = eventField(v, "type")
+		}
+		if streamType == "" && strings.HasPrefix(arn, "arn:aws:dynamodb:") {
+			streamType = "dynamodb"
+		}
+		if streamType == "dynamodb" {
+			if !strings.HasPrefix(arn, "arn:") {
+				arn = fmt.Sprintf("arn:aws:dynamodb:%s:%s:table/%s-table/stream/2021-01-01T00:00:00.000", region, sampleAccountId, key)
+			}
+			return DynamoDBStreamEvent(arn, region, string(body)), true
+		}
+		if !strings.HasPrefix(arn, "arn:") {
+			arn = fmt.Sprintf("arn:aws:kinesis:%s:%s:stream/%s-stream", region, sampleAccountId, key)
+		}
+		return KinesisEvent(arn, region, body), true
+	case "s3":
+		bucket, _ := v.(string)
+		eventName := "ObjectCreated:Put"
+		if bucket == "" {
+			bucket = eventField(v, "bucket")
+			if name := eventField(v, "event"); name != "" {
+				eventName = strings.TrimPrefix(name, "s3:")
+				eventName = strings.Replace(eventName, "ObjectRemoved:*", "ObjectRemoved:Delete", 1)
+				eventName = strings.Replace(eventName, ":*", ":Put", 1)
+			}
+		}
+		return S3Event(bucket, "sample.json", eventName, region), true
+	case "sns":
+		topic, _ := v.(string)
+		if topic == "" {
+			topic = eventField(v, "arn")
+		}
+		if topic == "" {
+			topic = eventField(v, "topicName")
+		}
+		if !strings.HasPrefix(topic, "arn:") {
+			topic = fmt.Sprintf("arn:aws:sns:%s:%s:%s", region, sampleAccountId, topic)
+		}
+		return SNSEvent(topic, string(body)), true
+	case "schedule":
+		return ScheduleEvent(fmt.Sprintf("arn:aws:events:%s:%s:rule/%s-schedule", region, sampleAccountId, key), region), true
+	case "eventBridge":
+		m, _ := v.(map[interface{}]interface{})
+		source, detailType := eventFirst(m["pattern"], "source"), eventFirst(m["pattern"], "detail-type")
+		if source == "" {
+			source = "custom"
+		}
+		if detailType == "" {
+			detailType = "Sample Event"
+		}
+		return EventBridgeEvent(source, detailType, body, region), true
+	}
+	return nil, false
+}
+
+// SampleEvents generates a payload for each of the function's events of a supported kind, in the config's order
+func (w *Wrapper) SampleEvents(key string, body []byte) ([]EventFixture, error) {
+	f, ok := w.stack.Functions[key]
+	if !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	var fixtures []EventFixture
+	for _, e := range f.Events {
+		for kind, v := range e {
+			if payload, ok := w.sampleEvent(key, kind, v, body); ok {
+				fixtures = append(fixtures, EventFixture{Kind: kind, Payload: payload})
+			}
+		}
+	}
+	return fixtures, nil
+}
+
+// SampleEvent generates an event for the first of the function's http, httpApi, sqs, stream, s3, sns, schedule or
+// eventBridge events, with body as the request body, message or record data
+func (w *Wrapper) SampleEvent(key string, body []byte) ([]byte, error) {
+	fixtures, err := w.SampleEvents(key, body)
+	if err != nil {
+		return nil, err
+	}
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("function %s has no event sample events can be generated for", key)
+	}
+	return fixtures[0].Payload, nil
+}
@@ -0,0 +1,83 @@
+package sls
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// heavier builders are started first so they don't end up as the long tail of the build phase
+var platformWeights = map[string]int{
+	"java8":  3,
+	"java11": 3,
+	"csharp": 3,
+	"golang": 2,
+	"nodejs": 1,
+	"python": 1,
+}
+
+type buildJob struct {
+	name   string
+	weight int
+	build  func(log *buildLog) error
+}
+
+func (w *Wrapper) maxParallelBuilds() int {
+	if w.MaxParallelBuilds > 0 {
+		return w.MaxParallelBuilds
+	}
+	return runtime.NumCPU()
+}
+
+func (w *Wrapper) runBuildJobs(ctx context.Context, jobs []buildJob) ([]BuildResult, error) {
+	order := make([]int, len(jobs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return jobs[order[i]].weight > jobs[order[j]].weight })
+
+	results := make([][]BuildResult, len(jobs))
+	errs := make([]error, len(jobs))
+
+	var mu sync.Mutex
+	failed := false
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, w.maxParallelBuilds())
+	for _, i := range order {
+		sem <- struct{}{}
+
+		mu.Lock()
+		stop := failed
+		mu.Unlock()
+		if stop {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			res, err := w.runBuild(ctx, jobs[i].name, jobs[i].build)
+			results[i], errs[i] = res, err
+			if err != nil {
+				mu.Lock()
+				failed = true
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var all []BuildResult
+	for i := range jobs {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		all = append(all, results[i]...)
+	}
+	return all, nil
+}
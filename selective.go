@@ -0,0 +1,184 @@
+package sls
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// compiledPlatforms build their functions from the whole platform dir, the others load their handler's file
+var compiledPlatforms = map[string]bool{"java8": true, "java11": true, "csharp": true, "golang": true}
+
+// deployHashes hashes what only a full deploy can apply, the config, options and layers, apart from each
+// function's inputs, its handler's files or compiled platform dir, prebuilt artifact or image, along with the code
+// the functions share. A function whose sources can't be found has no hash, so it takes a full deploy
+func (w *Wrapper) deployHashes() (string, map[string]string, error) {
+	h := sha256.New()
+	err := hashFile(h, w.configPath())
+	if err != nil {
+		return "", nil, err
+	}
+	opts := make([]string, 0, len(w.Opts))
+	for opt, val := range w.Opts {
+		// image digests change with the images' sources, which the functions' hashes cover
+		if !strings.HasPrefix(opt, imageOpt("")) {
+			opts = append(opts, opt+"="+val)
+		}
+	}
+	sort.Strings(opts)
+	json.NewEncoder(h).Encode(opts)
+
+	layers := make([]string, 0, len(w.stack.Layers))
+	for _, layer := range w.stack.Layers {
+		if layer.Path != "" {
+			layers = append(layers, layer.Path)
+		}
+	}
+	sort.Strings(layers)
+	for _, layerPath := range layers {
+		err = hashDir(h, filepath.Join(w.yamlDirPath, layerPath))
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	configHash := hex.EncodeToString(h.Sum(nil))
+
+	dirs, err := w.discoverPlatforms()
+	if err != nil {
+		return "", nil, err
+	}
+	files, err := w.sourceFiles()
+	if err != nil {
+		return "", nil, err
+	}
+	images := make(map[string]bool)
+	for _, key := range w.imageFunctions() {
+		images[key] = true
+	}
+
+	// the files a function's handler or platform dir claims are its own, the rest are shared by all functions
+	own := make(map[string][]string)
+	claimed := make(map[string]bool)
+	for key, f := range w.stack.Functions {
+		if images[key] || w.PrebuiltArtifacts[key] != "" {
+			continue
+		}
+		runtime := w.functionRuntime(f)
+		platform := runtimePlatform(runtime)
+		for rel := range files {
+			mine := false
+			if compiledPlatforms[platform] {
+				for _, d := range dirs {
+					if d.platform == platform && (d.name == d.platform || d.name == runtime) && strings.HasPrefix(rel, d.name+"/") {
+						mine = true
+					}
+				}
+			} else if f.Handler != "" {
+				mine = strings.TrimSuffix(rel, path.Ext(rel)) == handlerFile(f.Handler)
+			}
+			if mine {
+				own[key] = append(own[key], rel)
+				claimed[rel] = true
+			}
+		}
+	}
+	shared := sha256.New()
+	for _, rel := range sortedPaths(files) {
+		if !claimed[rel] {
+			io.WriteString(shared, rel+"\x00"+files[rel]+"\x00")
+		}
+	}
+	sharedHash := hex.EncodeToString(shared.Sum(nil))
+
+	functions := make(map[string]string)
+	for key := range w.stack.Functions {
+		h := sha256.New()
+		switch {
+		case images[key]:
+			err = hashDir(h, filepath.Join(w.yamlDirPath, key))
+		case w.PrebuiltArtifacts[key] != "":
+			err = hashFile(h, w.PrebuiltArtifacts[key])
+		case len(own[key]) == 0:
+			functions[key] = ""
+			continue
+		default:
+			sort.Strings(own[key])
+			for _, rel := range own[key] {
+				io.WriteString(h, rel+"\x00"+files[rel]+"\x00")
+			}
+			io.WriteString(h, sharedHash)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		functions[key] = hex.EncodeToString(h.Sum(nil))
+	}
+	return configHash, functions, nil
+}
+
+// deployChangedFunctions updates the functions that changed since the last deploy with sls deploy function, it
+// reports false when a full deploy is needed instead
+func (w *Wrapper) deployChangedFunctions(ctx context.Context) (*DeployResult, bool, error) {
+	state, err := w.loadDeployState()
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if state.Result == nil || state.ConfigHash == "" {
+		return nil, false, nil
+	}
+
+	configHash, functions, err := w.deployHashes()
+	if err != nil {
+		return nil, false, err
+	}
+	if configHash != state.ConfigHash || len(functions) != len(state.FunctionHashes) {
+		return nil, false, nil
+	}
+	var changed []string
+	for key, sum := range functions {
+		previous, ok := state.FunctionHashes[key]
+		if !ok || sum == "" {
+			return nil, false, nil
+		}
+		if sum != previous {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+
+	if len(changed) == 0 {
+		fmt.Fprintf(os.Stderr, "stack %s is up to date, skipping deploy\n", w.StackId())
+		return state.Result, true, nil
+	}
+	fmt.Fprintf(os.Stderr, "updating the changed functions of stack %s: %s\n", w.StackId(), strings.Join(changed, ", "))
+	results, err := w.deployFunctions(ctx, changed)
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := *state.Result
+	result.Builds = results
+	err = w.recordDeployment(&result)
+	if err != nil {
+		return nil, false, err
+	}
+
+	state.FunctionHashes = functions
+	state.Result = &result
+	state.DeployedAt = time.Now()
+	// the artifacts recorded by the last full deploy are stale now
+	state.SourceHash = ""
+	return &result, true, w.saveDeployState(state)
+}
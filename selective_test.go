@@ -0,0 +1,69 @@
+package sls
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeployHashes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sls-selective")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config := strings.Replace(nodeConfig, "functions:\n", "functions:\n  missing:\n    handler: missing.handler\n", 1)
+	w := newTestWrapper(t, dir, map[string]string{
+		YamlName:          config,
+		"handler.js":      "exports.hello = require('./lib/util').hello\n",
+		"echo/handler.js": "exports.echo = async e => e\n",
+		"lib/util.js":     "exports.hello = async () => 'hello'\n",
+	})
+	configHash, base, err := w.deployHashes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base["hello"] == "" || base["echo"] == "" {
+		t.Fatalf("functions with handler files have no hash: %v", base)
+	}
+	if base["missing"] != "" {
+		t.Errorf("a function without sources has hash %s, expected none to force a full deploy", base["missing"])
+	}
+
+	tests := []struct {
+		file    string
+		changed []string
+	}{
+		{"echo/handler.js", []string{"echo"}},
+		{"handler.js", []string{"hello"}},
+		// the functions share the code their handlers don't claim
+		{"lib/util.js", []string{"echo", "hello"}},
+	}
+	for _, test := range tests {
+		p := filepath.Join(dir, filepath.FromSlash(test.file))
+		old, err := ioutil.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, p, "changed\n")
+		hash, functions, err := w.deployHashes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hash != configHash {
+			t.Errorf("changing %s changed the config hash", test.file)
+		}
+		var changed []string
+		for _, key := range []string{"echo", "hello"} {
+			if functions[key] != base[key] {
+				changed = append(changed, key)
+			}
+		}
+		if strings.Join(changed, ",") != strings.Join(test.changed, ",") {
+			t.Errorf("changing %s changed the functions %v, expected %v", test.file, changed, test.changed)
+		}
+		writeFile(t, p, string(old))
+	}
+}
@@ -0,0 +1,154 @@
+package sls
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sigV4Algorithm = "AWS4-HMAC-SHA256"
+	amzDateLayout  = "20060102T150405Z"
+	// credentialsRefreshMargin renews temporary credentials this long before they expire
+	credentialsRefreshMargin = 5 * time.Minute
+)
+
+type awsCredentials struct {
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      *time.Time
+}
+
+func (c *awsCredentials) expired() bool {
+	return c.Expiration != nil && time.Until(*c.Expiration) < credentialsRefreshMargin
+}
+
+var (
+	credentialsMu sync.Mutex
+	// credentialsCache holds the credentials exported by the aws cli per profile
+	credentialsCache = make(map[string]*awsCredentials)
+)
+
+// credentials resolves the credentials the aws cli would use, the environment's are used as is and anything else, such
+// as sso or assumed roles, is exported by the cli once and cached until it expires
+func (w *Wrapper) credentials(ctx context.Context) (*awsCredentials, error) {
+	profile, hasProfile := w.Opts["aws-profile"]
+	if !hasProfile && os.Getenv("AWS_ACCESS_KEY_ID") != "" && os.Getenv("AWS_SECRET_ACCESS_KEY") != "" {
+		return &awsCredentials{
+			AccessKeyId:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+	if w.LocalStackEndpoint != "" && !hasProfile {
+		return &awsCredentials{AccessKeyId: "test", SecretAccessKey: "test"}, nil
+	}
+
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+	if creds, ok := credentialsCache[profile]; ok && !creds.expired() {
+		return creds, nil
+	}
+
+	args := []string{"configure", "export-credentials", "--format", "process"}
+	if hasProfile {
+		args = append(args, "--profile", profile)
+	}
+	var stderr bytes.Buffer
+	out, err := w.execCmdOutput(ctx, []string{}, w.yamlDirPath, ioutil.Discard, &stderr, "aws", args...)
+	if err != nil {
+		return nil, &awsError{args: args, err: err, stderr: strings.TrimSpace(stderr.String())}
+	}
+	creds := &awsCredentials{}
+	err = json.Unmarshal([]byte(out), creds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the exported aws credentials: %v", err)
+	}
+	credentialsCache[profile] = creds
+	return creds, nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// uriEncode escapes everything but the unreserved characters, and slashes when keepSlash is set
+func uriEncode(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' || (keepSlash && c == '/') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// signRequest signs a request without a query string with signature version 4, services other than s3 expect the
+// already escaped path to be escaped again
+func signRequest(req *http.Request, body []byte, creds *awsCredentials, region string, service string, now time.Time) {
+	amzDate := now.UTC().Format(amzDateLayout)
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.EscapedPath(), true),
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, creds.AccessKeyId, scope, signedHeaders, signature))
+}
@@ -0,0 +1,105 @@
+package sls
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// the vectors of the aws signature version 4 test suite that sign a path without a query string
+var sigV4Vectors = []struct {
+	name      string
+	method    string
+	headers   [][2]string
+	body      string
+	token     string
+	signature string
+}{
+	{
+		name:      "get-vanilla",
+		method:    http.MethodGet,
+		signature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+	},
+	{
+		name:      "post-vanilla",
+		method:    http.MethodPost,
+		signature: "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+	},
+	{
+		name:      "get-header-key-duplicate",
+		method:    http.MethodGet,
+		headers:   [][2]string{{"My-Header1", "value2"}, {"My-Header1", "value2"}, {"My-Header1", "value1"}},
+		signature: "c9d5ea9f3f72853aea855b47ea873832890dbdd183b4468f858259531a5138ea",
+	},
+	{
+		name:      "get-header-value-order",
+		method:    http.MethodGet,
+		headers:   [][2]string{{"My-Header1", "value4"}, {"My-Header1", "value1"}, {"My-Header1", "value3"}, {"My-Header1", "value2"}},
+		signature: "08c7e5a9acfcfeb3ab6b2185e75ce8b1deb5e634ec47601a50643f830c755c01",
+	},
+	{
+		name:      "post-x-www-form-urlencoded",
+		method:    http.MethodPost,
+		headers:   [][2]string{{"Content-Type", "application/x-www-form-urlencoded"}},
+		body:      "Param1=value1",
+		signature: "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+	},
+	{
+		name:      "post-x-www-form-urlencoded-parameters",
+		method:    http.MethodPost,
+		headers:   [][2]string{{"Content-Type", "application/x-www-form-urlencoded; charset=utf8"}},
+		body:      "Param1=value1",
+		signature: "1a72ec8f64bd914b0e42e42607c7fbce7fb2c7465f63e3092b3b0d39fa77a6fe",
+	},
+	{
+		name:   "post-sts-header-after",
+		method: http.MethodPost,
+		token: "AQoDYXdzEPT//////////wEXAMPLEtc764bNrC9SAPBSM22wDOk4x4HIZ8j4FZTwdQWLWsKWHGBuFqwAeMicRXmxfpSPfIeoIYRqTfl" +
+			"fKD8YUuwthAx7mSEI/qkPpKPi/kMcGdQrmGdeehM4IC1NtBmUpp2wUE8phUZampKsburEDy0KPkyQDYwT7WZ0wq5VSXDvp75YU9HFvlRd8Tx6q6" +
+			"fE8YQcHNVXAkiY9q6d+xo0rKwT38xVqr7ZD0u0iPPkUL64lIZbqBAz+scqKmlzm8FDrypNC9Yjc8fPOLn9FX9KSYvKTr4rvx3iSIlTJabIQwj2I" +
+			"CCR/oLxBA==",
+		signature: "85d96828115b5dc0cfc3bd16ad9e210dd772bbebba041836c64533a82be05ead",
+	},
+}
+
+func TestSignRequestVectors(t *testing.T) {
+	now, err := time.Parse(amzDateLayout, "20150830T123600Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range sigV4Vectors {
+		t.Run(v.name, func(t *testing.T) {
+			req, err := http.NewRequest(v.method, "https://example.amazonaws.com/", strings.NewReader(v.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, h := range v.headers {
+				req.Header.Add(h[0], h[1])
+			}
+			creds := &awsCredentials{
+				AccessKeyId:     "AKIDEXAMPLE",
+				SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+				SessionToken:    v.token,
+			}
+			signRequest(req, []byte(v.body), creds, "us-east-1", "service", now)
+
+			auth := req.Header.Get("Authorization")
+			if !strings.HasPrefix(auth, sigV4Algorithm+" Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, ") {
+				t.Errorf("unexpected credential scope in %s", auth)
+			}
+			if !strings.HasSuffix(auth, "Signature="+v.signature) {
+				t.Errorf("expected signature %s, got %s", v.signature, auth)
+			}
+		})
+	}
+}
+
+func TestURIEncodeEscapesPathAgain(t *testing.T) {
+	// lambda signs the escaped path of a qualified function name escaped once more
+	got := uriEncode("/2015-03-31/functions/hello%3Aprod/invocations", true)
+	want := "/2015-03-31/functions/hello%253Aprod/invocations"
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,144 @@
+package sls
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Sample is one invocation along with what was invoked
+type Sample struct {
+	Meta       BenchmarkMeta
+	Invocation Invocation
+}
+
+// ResultSink receives the samples of InvokeN and Load as the invocations complete, calls to it are serialized and
+// Flush is called once a run is done
+type ResultSink interface {
+	WriteSample(s Sample) error
+	Flush() error
+}
+
+// exportedSample is a sample's json line, the meta's and the invocation's fields side by side
+type exportedSample struct {
+	exportedMeta
+	exportedInvocation
+}
+
+// WriterSink writes samples as json lines, or csv rows under a header, to an io.Writer
+type WriterSink struct {
+	out    io.Writer
+	csv    *csv.Writer
+	header bool
+}
+
+func NewJSONSink(out io.Writer) *WriterSink {
+	return &WriterSink{out: out}
+}
+
+func NewCSVSink(out io.Writer) *WriterSink {
+	return &WriterSink{out: out, csv: csv.NewWriter(out)}
+}
+
+// StdoutSink prints the samples as json lines
+func StdoutSink() *WriterSink {
+	return NewJSONSink(os.Stdout)
+}
+
+func (s *WriterSink) WriteSample(sample Sample) error {
+	inv := exportInvocation(sample.Invocation)
+	if s.csv == nil {
+		data, err := json.Marshal(exportedSample{exportedMeta(sample.Meta), inv})
+		if err != nil {
+			return err
+		}
+		_, err = s.out.Write(append(data, '\n'))
+		return err
+	}
+
+	if !s.header {
+		err := s.csv.Write(csvHeader)
+		if err != nil {
+			return err
+		}
+		s.header = true
+	}
+	return s.csv.Write(csvRow(sample.Meta, inv))
+}
+
+func (s *WriterSink) Flush() error {
+	if s.csv == nil {
+		return nil
+	}
+	s.csv.Flush()
+	return s.csv.Error()
+}
+
+// FileSink writes the samples to a .csv file, or a file of json lines for any other extension
+type FileSink struct {
+	*WriterSink
+	f *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Ext(path) == ".csv" {
+		return &FileSink{WriterSink: NewCSVSink(f), f: f}, nil
+	}
+	return &FileSink{WriterSink: NewJSONSink(f), f: f}, nil
+}
+
+func (s *FileSink) Close() error {
+	err := s.Flush()
+	closeErr := s.f.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// sampleWriter serializes a run's writes to the wrapper's sink, a failing sink is reported once and then skipped so
+// it doesn't fail the run
+type sampleWriter struct {
+	sink   ResultSink
+	meta   BenchmarkMeta
+	mu     sync.Mutex
+	failed bool
+}
+
+func (w *Wrapper) newSampleWriter(meta BenchmarkMeta) *sampleWriter {
+	return &sampleWriter{sink: w.Sink, meta: meta}
+}
+
+func (sw *sampleWriter) write(inv Invocation) {
+	if sw.sink == nil {
+		return
+	}
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.failed {
+		return
+	}
+	err := sw.sink.WriteSample(Sample{Meta: sw.meta, Invocation: inv})
+	if err != nil {
+		sw.failed = true
+		fmt.Fprintf(os.Stderr, "failed to write sample of %s to the result sink: %v\n", sw.meta.Function, err)
+	}
+}
+
+func (sw *sampleWriter) flush() {
+	if sw.sink == nil || sw.failed {
+		return
+	}
+	err := sw.sink.Flush()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to flush the result sink: %v\n", err)
+	}
+}
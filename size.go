@@ -0,0 +1,71 @@
+package sls
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	maxZippedSize   = 50 * 1024 * 1024
+	maxUnzippedSize = 250 * 1024 * 1024
+	sizeWarnRatio   = 0.9
+)
+
+func (w *Wrapper) validateArtifactSizes(results []BuildResult) error {
+	for _, r := range results {
+		if r.Artifact == "" {
+			continue
+		}
+		owner := r.Runtime
+		if len(r.Functions) > 0 {
+			owner = strings.Join(r.Functions, ", ")
+		}
+		err := w.validateArtifactSize(r.Artifact, owner)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Wrapper) validateArtifactSize(artifact string, owner string) error {
+	info, err := os.Stat(artifact)
+	if err != nil {
+		return err
+	}
+	unzipped, err := unzippedSize(artifact)
+	if err != nil {
+		return err
+	}
+
+	provider := w.Provider()
+	maxZipped, maxUnzipped := provider.ArtifactLimits()
+	if maxZipped > 0 && info.Size() > maxZipped {
+		return fmt.Errorf("%s: package %s is %d bytes zipped, exceeding the %d bytes limit", owner, artifact, info.Size(), maxZipped)
+	}
+	if maxUnzipped > 0 && int64(unzipped) > maxUnzipped {
+		return fmt.Errorf("%s: package %s is %d bytes unzipped, exceeding the %d bytes limit", owner, artifact, unzipped, maxUnzipped)
+	}
+
+	if (maxZipped > 0 && float64(info.Size()) > float64(maxZipped)*sizeWarnRatio) ||
+		(maxUnzipped > 0 && float64(unzipped) > float64(maxUnzipped)*sizeWarnRatio) {
+		fmt.Fprintf(os.Stderr, "warning: %s: package %s is close to the %s size limits (%d bytes zipped, %d bytes unzipped)\n", owner, artifact, provider.Name(), info.Size(), unzipped)
+	}
+	return nil
+}
+
+func unzippedSize(artifact string) (uint64, error) {
+	r, err := zip.OpenReader(artifact)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	var size uint64
+	for _, f := range r.File {
+		size += f.UncompressedSize64
+	}
+	return size, nil
+}
@@ -0,0 +1,77 @@
+package sls
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// limitedProvider has artifact limits small enough for test artifacts to exceed
+type limitedProvider struct {
+	genericProvider
+	zipped   int64
+	unzipped int64
+}
+
+func (p limitedProvider) ArtifactLimits() (int64, int64) {
+	return p.zipped, p.unzipped
+}
+
+func TestValidateArtifactSize(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "sls-size")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	// zeros compress far below their unzipped size
+	src := filepath.Join(tmp, "zeros")
+	err = ioutil.WriteFile(src, make([]byte, 4096), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifact := filepath.Join(tmp, "deploy.zip")
+	err = zipArtifact(artifact, []artifactFile{{src: src, name: "zeros"}}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		zipped   int64
+		unzipped int64
+		err      string
+	}{
+		{name: "within", zipped: 1024, unzipped: 8192},
+		{name: "unlimited"},
+		{name: "zipped", zipped: 16, unzipped: 8192, err: "bytes zipped, exceeding the 16 bytes limit"},
+		{name: "unzipped", zipped: 1024, unzipped: 4095, err: "4096 bytes unzipped, exceeding the 4095 bytes limit"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider := limitedProvider{genericProvider: genericProvider{name: "limited-" + test.name}, zipped: test.zipped, unzipped: test.unzipped}
+			RegisterProvider(provider)
+			w := &Wrapper{provider: provider.Name()}
+
+			err := w.validateArtifactSize(artifact, "hello")
+			if test.err == "" {
+				if err != nil {
+					t.Errorf("expected the artifact to pass, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected an error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestAWSArtifactLimits(t *testing.T) {
+	zipped, unzipped := awsProvider{}.ArtifactLimits()
+	if zipped != 50*1024*1024 || unzipped != 250*1024*1024 {
+		t.Errorf("expected lambda's 50 MB zipped and 250 MB unzipped limits, got %d and %d", zipped, unzipped)
+	}
+}
@@ -0,0 +1,162 @@
+// Package slstest has helpers for testing code built on the sls package
+package slstest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/nuweba/sls"
+)
+
+// Call is a recorded call to a Fake, Key is the function key or name it was for
+type Call struct {
+	Method  string
+	Key     string
+	Payload []byte
+}
+
+// String is for test failure messages
+func (c Call) String() string {
+	if c.Key == "" {
+		return c.Method
+	}
+	return fmt.Sprintf("%s(%s)", c.Method, c.Key)
+}
+
+// Fake implements sls.Deployer and sls.Invoker in memory, the exported fields are its canned responses and are to
+// be set before it's used
+type Fake struct {
+	DeployResult *sls.DeployResult
+	DeployErr    error
+	RemoveErr    error
+	// Responses and Errors are by function key, a key without either gets an empty response
+	Responses map[string]*sls.InvokeResult
+	Errors    map[string]error
+
+	mu       sync.Mutex
+	calls    []Call
+	deployed bool
+}
+
+var (
+	_ sls.Deployer = (*Fake)(nil)
+	_ sls.Invoker  = (*Fake)(nil)
+)
+
+func (f *Fake) record(method string, key string, payload []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, Call{Method: method, Key: key, Payload: append([]byte(nil), payload...)})
+}
+
+// Calls returns the calls made so far in order
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// CallsTo returns the calls of one method
+func (f *Fake) CallsTo(method string) []Call {
+	var calls []Call
+	for _, c := range f.Calls() {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Deployed reports whether the last successful DeployStackContext wasn't followed by a RemoveStackContext
+func (f *Fake) Deployed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.deployed
+}
+
+func (f *Fake) DeployStackContext(ctx context.Context) (*sls.DeployResult, error) {
+	f.record("DeployStackContext", "", nil)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if f.DeployErr != nil {
+		return nil, f.DeployErr
+	}
+	f.mu.Lock()
+	f.deployed = true
+	f.mu.Unlock()
+	if f.DeployResult == nil {
+		return &sls.DeployResult{}, nil
+	}
+	return f.DeployResult, nil
+}
+
+func (f *Fake) DeployFunction(ctx context.Context, name string) error {
+	f.record("DeployFunction", name, nil)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.DeployErr
+}
+
+func (f *Fake) RemoveStackContext(ctx context.Context) error {
+	f.record("RemoveStackContext", "", nil)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if f.RemoveErr != nil {
+		return f.RemoveErr
+	}
+	f.mu.Lock()
+	f.deployed = false
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *Fake) response(key string) (*sls.InvokeResult, error) {
+	if err := f.Errors[key]; err != nil {
+		return nil, err
+	}
+	if r, ok := f.Responses[key]; ok {
+		return r, nil
+	}
+	return &sls.InvokeResult{}, nil
+}
+
+// Invoke returns the key's canned response, failing like the real invoke when the response has a function error
+func (f *Fake) Invoke(ctx context.Context, key string, payload []byte) (*sls.InvokeResult, error) {
+	f.record("Invoke", key, payload)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r, err := f.response(key)
+	if err != nil {
+		return nil, err
+	}
+	if r.Error != nil {
+		return r, r.Error
+	}
+	return r, nil
+}
+
+// InvokeAsync returns the key's canned response as the async result when opts asks to wait for it
+func (f *Fake) InvokeAsync(ctx context.Context, key string, payload []byte, opts *sls.AsyncOptions) (*sls.AsyncResult, error) {
+	f.record("InvokeAsync", key, payload)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r, err := f.response(key)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		return &sls.AsyncResult{}, nil
+	}
+	result := &sls.AsyncResult{RequestID: r.RequestID, Condition: "Success", Response: r.Response, Error: r.Error, Logs: r.Logs}
+	if r.Error != nil {
+		result.Condition = "RetriesExhausted"
+		return result, r.Error
+	}
+	return result, nil
+}
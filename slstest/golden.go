@@ -0,0 +1,106 @@
+package slstest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nuweba/sls"
+)
+
+const goldenExt = ".golden"
+
+// UpdateGolden rewrites the expected parses of CheckGolden instead of comparing against them
+var UpdateGolden = flag.Bool("slstest.update", false, "rewrite the expected parses of golden sls outputs")
+
+func expectedParse(kind string, out string) ([]byte, error) {
+	parsed, err := sls.ParseOutput(kind, out)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(parsed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// CaptureGolden runs the sls commands of the given output kinds, all of them by default, and writes each raw output
+// to <dir>/<kind>.golden along with its parse in <dir>/<kind>.json, key is the function of per function commands
+func CaptureGolden(ctx context.Context, w *sls.Wrapper, dir string, key string, kinds ...string) error {
+	if len(kinds) == 0 {
+		kinds = sls.OutputKinds()
+	}
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	for _, kind := range kinds {
+		out, err := w.CaptureOutput(ctx, kind, key)
+		if err != nil {
+			return err
+		}
+		expected, err := expectedParse(kind, out)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, kind+goldenExt), []byte(out), 0644)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, kind+".json"), expected, 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckGolden parses every golden output in dir and compares the parse with the one recorded next to it, outputs
+// from several framework versions can be kept in subdirectories of their own
+func CheckGolden(t testing.TB, dir string) {
+	t.Helper()
+	goldens, err := filepath.Glob(filepath.Join(dir, "*"+goldenExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(goldens) == 0 {
+		t.Fatalf("no golden outputs in %s", dir)
+	}
+
+	for _, golden := range goldens {
+		kind := strings.TrimSuffix(filepath.Base(golden), goldenExt)
+		out, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := expectedParse(kind, string(out))
+		if err != nil {
+			t.Errorf("%s: %v", golden, err)
+			continue
+		}
+
+		expectedPath := strings.TrimSuffix(golden, goldenExt) + ".json"
+		if *UpdateGolden {
+			err = ioutil.WriteFile(expectedPath, got, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := ioutil.ReadFile(expectedPath)
+		if err != nil {
+			t.Errorf("%s: %v", golden, err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s parses differently than recorded in %s, got:\n%s", golden, expectedPath, got)
+		}
+	}
+}
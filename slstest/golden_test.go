@@ -0,0 +1,65 @@
+package slstest
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/nuweba/sls"
+)
+
+const goldenRoot = "testdata/golden"
+
+var (
+	captureDir      = flag.String("slstest.capture", "", "yaml dir of a deployed service to capture golden outputs from")
+	captureSuffix   = flag.String("slstest.suffix", "", "suffix the captured service was deployed with")
+	captureFunction = flag.String("slstest.function", "", "function to capture the per function outputs of")
+)
+
+var frameworkMajor = regexp.MustCompile(`(\d+)\.\d+\.\d+`)
+
+// TestGolden checks the outputs recorded from every framework version, one subdirectory per major version
+func TestGolden(t *testing.T) {
+	infos, err := ioutil.ReadDir(goldenRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, info := range infos {
+		if !info.IsDir() {
+			continue
+		}
+		dir := filepath.Join(goldenRoot, info.Name())
+		t.Run(info.Name(), func(t *testing.T) {
+			CheckGolden(t, dir)
+		})
+	}
+}
+
+// TestCaptureGolden records the outputs of the installed framework into the subdirectory of its major version, run it with
+// go test -run TestCaptureGolden -slstest.capture <dir> -slstest.suffix <suffix> -slstest.function <function>
+func TestCaptureGolden(t *testing.T) {
+	if *captureDir == "" {
+		t.Skip("no deployed service to capture from, set -slstest.capture")
+	}
+	out, err := exec.Command("sls", "--version").CombinedOutput()
+	if err != nil {
+		t.Fatalf("sls --version: %v: %s", err, out)
+	}
+	m := frameworkMajor.FindSubmatch(out)
+	if m == nil {
+		t.Fatalf("no framework version in %q", out)
+	}
+
+	w, err := sls.NewWithSuffix("aws", *captureDir, *captureSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CaptureGolden(context.Background(), w, filepath.Join(goldenRoot, "v"+string(m[1])), *captureFunction)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
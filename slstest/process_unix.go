@@ -0,0 +1,29 @@
+//go:build !windows
+// +build !windows
+
+package slstest
+
+import (
+	"os"
+	"syscall"
+)
+
+// holdMarker locks the marker, the lock goes away with the process however it exits so a reused pid can't hold it
+func holdMarker(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+// markerHeld tells whether a running watchdog still holds the marker
+func markerHeld(marker string, pid int) bool {
+	f, err := os.Open(marker)
+	if err != nil {
+		return true
+	}
+	defer f.Close()
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err != nil {
+		return true
+	}
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	return false
+}
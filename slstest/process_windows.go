@@ -0,0 +1,23 @@
+//go:build windows
+// +build windows
+
+package slstest
+
+import (
+	"os"
+)
+
+// holdMarker has nothing to lock the marker with on windows, which tells held markers by their pid
+func holdMarker(f *os.File) error {
+	return nil
+}
+
+// markerHeld opens a handle on the process, FindProcess fails when the process is gone
+func markerHeld(marker string, pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -0,0 +1,183 @@
+package slstest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/nuweba/sls"
+)
+
+const provider = "aws"
+
+func markerPrefix(dir string) string {
+	sum := sha256.Sum256([]byte(dir))
+	return filepath.Join(os.TempDir(), "slstest-"+hex.EncodeToString(sum[:8])+"-")
+}
+
+// markerPath is where the suffix of the dir's test stack is kept while the watchdog with the pid has it deployed,
+// test binaries of several packages may deploy the same dir at once
+func markerPath(dir string, pid int) string {
+	return markerPrefix(dir) + strconv.Itoa(pid) + ".suffix"
+}
+
+// removeStale removes the stacks previous runs left behind when their watchdog was killed before it could remove them,
+// markers still held by a running watchdog are its to remove
+func removeStale(dir string) {
+	markers, err := filepath.Glob(markerPrefix(dir) + "*.suffix")
+	if err != nil {
+		return
+	}
+	for _, marker := range markers {
+		pid, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(marker, markerPrefix(dir)), ".suffix"))
+		if err != nil || pid == os.Getpid() || markerHeld(marker, pid) {
+			continue
+		}
+		data, err := ioutil.ReadFile(marker)
+		if err != nil {
+			continue
+		}
+		stale, err := sls.NewWithSuffix(provider, dir, strings.TrimSpace(string(data)))
+		if err == nil {
+			err = stale.RemoveStackContext(context.Background())
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to remove the stack a previous test run left: %v\n", err)
+			continue
+		}
+		os.Remove(marker)
+	}
+}
+
+// createMarker writes the suffix to the marker of this process and holds it until the file is closed
+func createMarker(dir string, suffix string) (*os.File, error) {
+	f, err := os.OpenFile(markerPath(dir, os.Getpid()), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	err = holdMarker(f)
+	if err == nil {
+		_, err = f.WriteString(suffix)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return f, nil
+}
+
+// WithStack deploys the stack in dir once for the whole test binary, runs its tests and removes the stack, returning
+// the exit code for os.Exit. fn gets the wrapper before the deploy to configure it and keep it for the tests.
+// The tests run in a child process of the test binary, which stays behind as a watchdog that removes the stack once
+// the child exits, so tests that panic or exit don't leave it deployed. A watchdog that's killed leaves the stack to
+// the next run on the same dir
+func WithStack(m *testing.M, dir string, fn func(*sls.Wrapper)) int {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if suffix, ok := os.LookupEnv(stackSuffixEnv); ok {
+		return runTests(m, dir, suffix, fn)
+	}
+	return watchdog(dir, fn)
+}
+
+// stackSuffixEnv passes the suffix of the deployed stack from the watchdog to the child running the tests
+const stackSuffixEnv = "SLSTEST_STACK_SUFFIX"
+
+func runTests(m *testing.M, dir string, suffix string, fn func(*sls.Wrapper)) int {
+	w, err := sls.NewWithSuffix(provider, dir, suffix)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if fn != nil {
+		fn(w)
+	}
+	return m.Run()
+}
+
+// watchdog deploys the stack, runs the test binary again against it and removes the stack whichever way the run ends
+func watchdog(dir string, fn func(*sls.Wrapper)) int {
+	removeStale(dir)
+
+	w, err := sls.New(provider, dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if fn != nil {
+		fn(w)
+	}
+	marker, err := createMarker(dir, w.Suffix())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer marker.Close()
+
+	// interrupts cancel the deploy or kill the child, the stack is removed either way
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	code := 1
+	_, err = w.DeployStackContext(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to deploy the test stack: %v\n", err)
+	} else {
+		code = runChild(ctx, w.Suffix())
+	}
+
+	err = w.RemoveStackContext(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to remove the test stack %s: %v\n", w.StackId(), err)
+		return 1
+	}
+	os.Remove(marker.Name())
+	return code
+}
+
+// runChild runs the test binary with the same arguments against the deployed stack and returns its exit code
+func runChild(ctx context.Context, suffix string) int {
+	path, err := os.Executable()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	cmd := exec.CommandContext(ctx, path, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), stackSuffixEnv+"="+suffix)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err == nil {
+		return 0
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+	fmt.Fprintf(os.Stderr, "the tests didn't exit: %v\n", err)
+	return 1
+}
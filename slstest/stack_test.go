@@ -0,0 +1,104 @@
+//go:build !windows
+// +build !windows
+
+package slstest
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// watchdogDirEnv makes TestMain run the tests under WithStack with the service in the dir
+const watchdogDirEnv = "SLSTEST_WATCHDOG_DIR"
+
+func TestMain(m *testing.M) {
+	if dir := os.Getenv(watchdogDirEnv); dir != "" {
+		os.Exit(WithStack(m, dir, nil))
+	}
+	os.Exit(m.Run())
+}
+
+// fakeSls logs the commands it runs and deploys by printing service information
+const fakeSls = `#!/bin/sh
+echo "$1" >> "$SLS_LOG"
+if [ "$1" = deploy ]; then
+	printf 'service: svc\nstage: dev\nregion: us-east-1\nstack: svc-dev\n'
+fi
+`
+
+// fakeAws answers every call with a deployed stack
+const fakeAws = `#!/bin/sh
+echo '{"Stacks": [{"StackStatus": "CREATE_COMPLETE", "Outputs": [{"OutputKey": "ServerlessDeploymentBucketName", "OutputValue": "bucket"}]}]}'
+`
+
+const watchdogConfig = `service: svc
+provider:
+  name: aws
+  runtime: nodejs14.x
+`
+
+// TestPanicUnderWatchdog only runs as the child of TestWithStackRemovesAfterPanic
+func TestPanicUnderWatchdog(t *testing.T) {
+	if os.Getenv(watchdogDirEnv) == "" {
+		t.Skip("runs under the watchdog of TestWithStackRemovesAfterPanic")
+	}
+	panic("test panicked")
+}
+
+func TestWithStackRemovesAfterPanic(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "slstest-watchdog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	bin := filepath.Join(tmp, "bin")
+	dir := filepath.Join(tmp, "service")
+	for _, d := range []string{bin, dir} {
+		err = os.MkdirAll(d, 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range map[string]string{"sls": fakeSls, "aws": fakeAws} {
+		err = ioutil.WriteFile(filepath.Join(bin, name), []byte(content), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "serverless.yml"), []byte(watchdogConfig), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(tmp, "sls.log")
+	cmd := exec.Command(path, "-test.run", "^TestPanicUnderWatchdog$")
+	cmd.Env = append(os.Environ(),
+		watchdogDirEnv+"="+dir,
+		"SLS_LOG="+log,
+		"PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("the panicking test passed:\n%s", out)
+	}
+
+	data, err := ioutil.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commands := strings.Fields(string(data))
+	if len(commands) == 0 || commands[len(commands)-1] != "remove" {
+		t.Errorf("the stack wasn't removed after the panic, sls ran %v:\n%s", commands, out)
+	}
+	if !strings.Contains(string(out), "test panicked") {
+		t.Errorf("the panic wasn't reported:\n%s", out)
+	}
+}
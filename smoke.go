@@ -0,0 +1,69 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var defaultProbePayload = []byte("{}")
+
+type SmokeResult struct {
+	Function string
+	Duration time.Duration
+	Response []byte
+	Err      error
+}
+
+type SmokeError struct {
+	Failed []SmokeResult
+}
+
+func (e *SmokeError) Error() string {
+	lines := make([]string, 0, len(e.Failed))
+	for _, r := range e.Failed {
+		lines = append(lines, fmt.Sprintf("%s: %v", r.Function, r.Err))
+	}
+	return "smoke test failed:\n  " + strings.Join(lines, "\n  ")
+}
+
+func (w *Wrapper) probePayload(key string) []byte {
+	if payload, ok := w.ProbePayloads[key]; ok {
+		return payload
+	}
+	if w.ProbePayload != nil {
+		return w.ProbePayload
+	}
+	return defaultProbePayload
+}
+
+// SmokeTest invokes every function once with its probe payload, a *SmokeError lists the functions that failed
+func (w *Wrapper) SmokeTest(ctx context.Context) ([]SmokeResult, error) {
+	err := w.requireAWS("smoke testing")
+	if err != nil {
+		return nil, err
+	}
+	var results []SmokeResult
+	var failed []SmokeResult
+	for _, key := range w.functionKeys() {
+		start := time.Now()
+		out, err := w.lambdaInvoke(ctx, w.functionName(key), w.probePayload(key), "RequestResponse", true)
+		r := SmokeResult{Function: key, Duration: time.Since(start), Err: err}
+		if err == nil {
+			r.Response = out.Payload
+			if out.FunctionError != "" {
+				r.Err = fmt.Errorf("%s error: %s\n%s", out.FunctionError, strings.TrimSpace(string(out.Payload)), out.logTail())
+			}
+		}
+		results = append(results, r)
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+
+	if len(failed) > 0 {
+		return results, &SmokeError{Failed: failed}
+	}
+	return results, nil
+}
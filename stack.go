@@ -0,0 +1,89 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultRemovalTimeout = 10 * time.Minute
+	stackPollInterval     = 5 * time.Second
+)
+
+type stackDescription struct {
+	StackName    string
+	StackId      string
+	StackStatus  string
+	CreationTime time.Time
+	Outputs      []struct {
+		OutputKey   string
+		OutputValue string
+		ExportName  string
+	}
+}
+
+func (w *Wrapper) describeStack(ctx context.Context, stackName string) (*stackDescription, error) {
+	var resp struct {
+		Stacks []stackDescription
+	}
+	err := w.awsCmd(ctx, &resp, "cloudformation", "describe-stacks", "--stack-name", stackName)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Stacks) == 0 {
+		return nil, nil
+	}
+	return &resp.Stacks[0], nil
+}
+
+// StackExists reports whether the suffixed stack is currently deployed
+func (w *Wrapper) StackExists(ctx context.Context) (bool, error) {
+	err := w.requireAWS("checking whether a stack exists")
+	if err != nil {
+		return false, err
+	}
+	stack, err := w.describeStack(ctx, w.StackName())
+	if isAwsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return stack != nil && stack.StackStatus != "DELETE_COMPLETE", nil
+}
+
+type deleteFailedError struct {
+	stackName string
+}
+
+func (e *deleteFailedError) Error() string {
+	return fmt.Sprintf("stack %s failed to delete", e.stackName)
+}
+
+func (w *Wrapper) waitStackDeleted(ctx context.Context) error {
+	timeout := w.RemovalTimeout
+	if timeout == 0 {
+		timeout = defaultRemovalTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		stack, err := w.describeStack(ctx, w.StackName())
+		if isAwsNotFound(err) || (err == nil && (stack == nil || stack.StackStatus == "DELETE_COMPLETE")) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("stack %s still exists after %v", w.StackName(), timeout)
+		}
+		if err != nil {
+			return err
+		}
+		if stack.StackStatus == "DELETE_FAILED" {
+			return &deleteFailedError{stackName: w.StackName()}
+		}
+
+		sleepContext(ctx, stackPollInterval)
+	}
+}
@@ -0,0 +1,130 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// StackSet deploys and removes several services together, for suites that need all of them up at once
+type StackSet struct {
+	Wrappers []*Wrapper
+	// MaxConcurrency limits the stacks deployed or removed at the same time, zero means no limit
+	MaxConcurrency int
+}
+
+type StackSetError struct {
+	// Errors is keyed by stack name
+	Errors map[string]error
+}
+
+func (e *StackSetError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("%s: %v", name, e.Errors[name]))
+	}
+	return fmt.Sprintf("%d stacks failed:\n  %s", len(names), strings.Join(lines, "\n  "))
+}
+
+func (s *StackSet) forEach(ctx context.Context, fn func(ctx context.Context, i int, w *Wrapper) error) []error {
+	limit := s.MaxConcurrency
+	if limit <= 0 {
+		limit = len(s.Wrappers)
+	}
+	errs := make([]error, len(s.Wrappers))
+	sem := make(chan struct{}, limit)
+
+	var wg sync.WaitGroup
+	for i, w := range s.Wrappers {
+		wg.Add(1)
+		go func(i int, w *Wrapper) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs[i] = fn(ctx, i, w)
+		}(i, w)
+	}
+	wg.Wait()
+	return errs
+}
+
+func (s *StackSet) collect(errs []error, into map[string]error) {
+	for i, err := range errs {
+		if err != nil {
+			into[s.Wrappers[i].StackName()] = err
+		}
+	}
+}
+
+// Deploy deploys every stack, when any of them fails the stacks this call created are removed again so nothing is
+// left half up, stacks that existed before are kept, as are those of providers that can't tell whether they did
+func (s *StackSet) Deploy(ctx context.Context) ([]*DeployResult, error) {
+	existed := make([]bool, len(s.Wrappers))
+	errs := s.forEach(ctx, func(ctx context.Context, i int, w *Wrapper) error {
+		var err error
+		existed[i], err = w.StackExists(ctx)
+		if _, unsupported := err.(*UnsupportedError); unsupported {
+			existed[i], err = true, nil
+		}
+		return err
+	})
+	failures := make(map[string]error)
+	s.collect(errs, failures)
+	if len(failures) > 0 {
+		return nil, &StackSetError{Errors: failures}
+	}
+
+	results := make([]*DeployResult, len(s.Wrappers))
+	errs = s.forEach(ctx, func(ctx context.Context, i int, w *Wrapper) error {
+		var err error
+		results[i], err = w.DeployStackContext(ctx)
+		return err
+	})
+	s.collect(errs, failures)
+	if len(failures) == 0 {
+		return results, nil
+	}
+
+	// a failed deploy may still have created its stack, so the new stacks are removed unless they never came up
+	cleanup := s.forEach(context.Background(), func(ctx context.Context, i int, w *Wrapper) error {
+		if existed[i] {
+			return nil
+		}
+		exists, err := w.StackExists(ctx)
+		if err != nil || !exists {
+			return err
+		}
+		return w.RemoveStackContext(ctx)
+	})
+	for i, err := range cleanup {
+		if err != nil {
+			name := s.Wrappers[i].StackName()
+			if deployErr, ok := failures[name]; ok {
+				failures[name] = fmt.Errorf("%v, cleanup failed: %v", deployErr, err)
+			} else {
+				failures[name] = fmt.Errorf("cleanup failed: %v", err)
+			}
+		}
+	}
+	return nil, &StackSetError{Errors: failures}
+}
+
+func (s *StackSet) Remove(ctx context.Context) error {
+	errs := s.forEach(ctx, func(ctx context.Context, i int, w *Wrapper) error {
+		return w.RemoveStackContext(ctx)
+	})
+	failures := make(map[string]error)
+	s.collect(errs, failures)
+	if len(failures) > 0 {
+		return &StackSetError{Errors: failures}
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package sls
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const stateDirName = ".sls-wrapper"
+
+var buildOutputNames = map[string]bool{
+	"bin":          true,
+	"obj":          true,
+	"target":       true,
+	"dist":         true,
+	"node_modules": true,
+	"deploy.zip":   true,
+	".serverless":  true,
+	stateDirName:   true,
+}
+
+// sourceSkipNames are never packaged from the service dir, node_modules is hashed through the lockfiles instead
+var sourceSkipNames = map[string]bool{
+	".git":         true,
+	".serverless":  true,
+	stateDirName:   true,
+	"node_modules": true,
+}
+
+// lockfileNames are hashed whatever the package patterns say, since they stand in for node_modules
+var lockfileNames = map[string]bool{
+	"package-lock.json":   true,
+	"npm-shrinkwrap.json": true,
+	"yarn.lock":           true,
+	"pnpm-lock.yaml":      true,
+}
+
+type deployState struct {
+	SourceHash string
+	// ConfigHash and FunctionHashes tell which functions SelectiveDeploy can update on their own
+	ConfigHash     string
+	FunctionHashes map[string]string
+	Artifacts      []BuildResult
+	Result         *DeployResult
+	DeployedAt     time.Time
+}
+
+func (w *Wrapper) stateDir() string {
+	return filepath.Join(w.yamlDirPath, stateDirName)
+}
+
+func (w *Wrapper) deployStatePath() string {
+	return filepath.Join(w.stateDir(), "deploy-"+w.instanceKey()+".json")
+}
+
+func (w *Wrapper) loadDeployState() (*deployState, error) {
+	data, err := ioutil.ReadFile(w.deployStatePath())
+	if err != nil {
+		return nil, err
+	}
+	state := &deployState{}
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (w *Wrapper) saveDeployState(state *deployState) error {
+	err := os.MkdirAll(w.stateDir(), 0755)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(w.deployStatePath(), data, 0644)
+}
+
+func (w *Wrapper) removeDeployState() error {
+	err := os.Remove(w.deployStatePath())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// deployIsFresh reports whether the sources and the previously built artifacts of this suffix are unchanged
+func (w *Wrapper) deployIsFresh() (*deployState, string, bool, error) {
+	sourceHash, err := w.sourceHash()
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	state, err := w.loadDeployState()
+	if os.IsNotExist(err) {
+		return nil, sourceHash, false, nil
+	}
+	if err != nil {
+		return nil, "", false, err
+	}
+	if state.SourceHash != sourceHash {
+		return state, sourceHash, false, nil
+	}
+
+	for _, a := range state.Artifacts {
+		if a.Artifact == "" {
+			continue
+		}
+		_, sum, err := fileChecksum(a.Artifact)
+		if err != nil || sum != a.Sha256 {
+			return state, sourceHash, false, nil
+		}
+	}
+	return state, sourceHash, true, nil
+}
+
+// sourceHash covers everything a deploy packages, the config and options, the service dir's files as the package
+// patterns select them, prebuilt artifacts, image sources and layers
+func (w *Wrapper) sourceHash() (string, error) {
+	h := sha256.New()
+
+	err := hashFile(h, w.configPath())
+	if err != nil {
+		return "", err
+	}
+
+	opts := make([]string, 0, len(w.Opts))
+	for opt, val := range w.Opts {
+		opts = append(opts, opt+"="+val)
+	}
+	sort.Strings(opts)
+	json.NewEncoder(h).Encode(opts)
+
+	prebuilt := make([]string, 0, len(w.PrebuiltArtifacts))
+	for key := range w.PrebuiltArtifacts {
+		prebuilt = append(prebuilt, key)
+	}
+	sort.Strings(prebuilt)
+	for _, key := range prebuilt {
+		io.WriteString(h, key+"\x00")
+		err = hashFile(h, w.PrebuiltArtifacts[key])
+		if err != nil {
+			return "", err
+		}
+	}
+
+	files, err := w.sourceFiles()
+	if err != nil {
+		return "", err
+	}
+	for _, rel := range sortedPaths(files) {
+		io.WriteString(h, rel+"\x00"+files[rel]+"\x00")
+	}
+	for _, key := range w.imageFunctions() {
+		err = hashDir(h, filepath.Join(w.yamlDirPath, key))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	layers := make([]string, 0, len(w.stack.Layers))
+	for _, layer := range w.stack.Layers {
+		if layer.Path != "" {
+			layers = append(layers, layer.Path)
+		}
+	}
+	sort.Strings(layers)
+	for _, layerPath := range layers {
+		err = hashDir(h, filepath.Join(w.yamlDirPath, layerPath))
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// generatedConfig tells the configs the wrapper writes next to the service's own
+func (w *Wrapper) generatedConfig(name string) bool {
+	return name != w.configName && strings.HasPrefix(name, "serverless-") && (strings.HasSuffix(name, ".yml") || strings.HasSuffix(name, ".yaml"))
+}
+
+// sourceFiles hashes every file of the service dir the package can contain, by its slash separated path relative to
+// the dir, skipping what the builders write into the platform dirs, the layer paths and the artifacts dir
+func (w *Wrapper) sourceFiles() (map[string]string, error) {
+	dirs, err := w.discoverPlatforms()
+	if err != nil {
+		return nil, err
+	}
+	// the builders write into the platform dirs and the layer paths
+	outputDirs := make(map[string]bool)
+	for _, d := range dirs {
+		outputDirs[d.path] = true
+	}
+	for _, l := range w.stack.Layers {
+		if l.Path != "" {
+			outputDirs[filepath.Join(w.yamlDirPath, l.Path)] = true
+		}
+	}
+	artifacts := w.artifactsRoot()
+
+	files := make(map[string]string)
+	err = filepath.Walk(w.yamlDirPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == w.yamlDirPath {
+			return nil
+		}
+		output := outputDirs[filepath.Dir(p)] && buildOutputNames[info.Name()]
+		if info.IsDir() {
+			if sourceSkipNames[info.Name()] || output || (artifacts != w.yamlDirPath && p == artifacts) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(w.yamlDirPath, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if output || w.generatedConfig(info.Name()) || (!lockfileNames[info.Name()] && !w.packagesFile(rel)) {
+			return nil
+		}
+
+		h := sha256.New()
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			io.WriteString(h, target)
+		} else {
+			err = hashFile(h, p)
+			if err != nil {
+				return err
+			}
+		}
+		files[rel] = hex.EncodeToString(h.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func sortedPaths(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hashDir hashes the files under dir by their slash separated paths relative to it, so the hash doesn't depend on
+// where the dir is checked out
+func hashDir(h io.Writer, dir string) error {
+	return filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if buildOutputNames[info.Name()] {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		io.WriteString(h, filepath.ToSlash(rel)+"\x00")
+		return hashFile(h, p)
+	})
+}
+
+func hashFile(h io.Writer, p string) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(h, f)
+	return err
+}
@@ -0,0 +1,84 @@
+package sls
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const nodeConfig = `service: hello-${opt:suffix}
+provider:
+  name: aws
+  runtime: nodejs14.x
+package:
+  patterns:
+    - '!docs/**'
+functions:
+  hello:
+    handler: handler.hello
+  echo:
+    handler: echo/handler.echo
+`
+
+func writeFile(t *testing.T, p string, content string) {
+	err := os.MkdirAll(filepath.Dir(p), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(p, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSourceHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sls-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	w := newTestWrapper(t, dir, map[string]string{
+		YamlName:                  nodeConfig,
+		"handler.js":              "exports.hello = require('./lib/util').hello\n",
+		"echo/handler.js":         "exports.echo = async e => e\n",
+		"lib/util.js":             "exports.hello = async () => 'hello'\n",
+		"docs/readme.md":          "# hello\n",
+		"package-lock.json":       "{}\n",
+		"node_modules/x/index.js": "module.exports = 1\n",
+	})
+	base, err := w.sourceHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file    string
+		changes bool
+	}{
+		{"lib/util.js", true},
+		{"package-lock.json", true},
+		{"assets/data.json", true},
+		{"docs/readme.md", false},
+		{"node_modules/x/index.js", false},
+		{".serverless/cloudformation-template-update-stack.json", false},
+		{"serverless-abc-package.yml", false},
+	}
+	for _, test := range tests {
+		p := filepath.Join(dir, filepath.FromSlash(test.file))
+		old, readErr := ioutil.ReadFile(p)
+		writeFile(t, p, "changed\n")
+		hash, err := w.sourceHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changes := hash != base; changes != test.changes {
+			t.Errorf("changing %s changed the source hash: %t, expected %t", test.file, changes, test.changes)
+		}
+		if readErr == nil {
+			writeFile(t, p, string(old))
+		} else {
+			os.Remove(p)
+		}
+	}
+}
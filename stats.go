@@ -0,0 +1,138 @@
+package sls
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const defaultHistogramBuckets = 10
+
+type HistogramBucket struct {
+	From  time.Duration
+	To    time.Duration
+	Count int
+}
+
+type LatencyStats struct {
+	Count  int
+	Errors int
+	Min    time.Duration
+	Max    time.Duration
+	Mean   time.Duration
+	StdDev time.Duration
+	P50    time.Duration
+	P90    time.Duration
+	P95    time.Duration
+	P99    time.Duration
+	P999   time.Duration
+	// Wall is the time all the invocations took together, Throughput is invocations per second over it
+	Wall       time.Duration
+	Throughput float64
+	// Histogram splits the range of latencies into equal buckets
+	Histogram []HistogramBucket
+	// Outliers are the indexes of the invocations slower than the third quartile by more than 1.5 interquartile ranges
+	Outliers []int
+}
+
+// percentile picks the nearest rank of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted))*p+0.999999) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+// histogram counts sorted latencies into n buckets of equal width between the smallest and the largest
+func histogram(sorted []time.Duration, n int) []HistogramBucket {
+	if len(sorted) == 0 || n < 1 {
+		return nil
+	}
+	min, max := sorted[0], sorted[len(sorted)-1]
+	width := (max - min) / time.Duration(n)
+	if width == 0 {
+		return []HistogramBucket{{From: min, To: max, Count: len(sorted)}}
+	}
+
+	buckets := make([]HistogramBucket, n)
+	for i := range buckets {
+		buckets[i].From = min + time.Duration(i)*width
+		buckets[i].To = buckets[i].From + width
+	}
+	buckets[n-1].To = max
+	for _, l := range sorted {
+		i := int((l - min) / width)
+		if i >= n {
+			i = n - 1
+		}
+		buckets[i].Count++
+	}
+	return buckets
+}
+
+// LatencyHistogram buckets the successful invocations' latencies, for other bucket counts than the stats' own
+func LatencyHistogram(invocations []Invocation, buckets int) []HistogramBucket {
+	var latencies []time.Duration
+	for _, inv := range invocations {
+		if inv.Err == nil {
+			latencies = append(latencies, inv.Latency)
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return histogram(latencies, buckets)
+}
+
+// latencyStats summarizes the successful invocations' latencies
+func latencyStats(invocations []Invocation, wall time.Duration) LatencyStats {
+	stats := LatencyStats{Count: len(invocations), Wall: wall}
+	var latencies []time.Duration
+	var total time.Duration
+	for _, inv := range invocations {
+		if inv.Err != nil {
+			stats.Errors++
+			continue
+		}
+		latencies = append(latencies, inv.Latency)
+		total += inv.Latency
+	}
+	if wall > 0 {
+		stats.Throughput = float64(len(invocations)) / wall.Seconds()
+	}
+	if len(latencies) == 0 {
+		return stats
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	stats.Min = latencies[0]
+	stats.Max = latencies[len(latencies)-1]
+	stats.Mean = total / time.Duration(len(latencies))
+	stats.P50 = percentile(latencies, 0.50)
+	stats.P90 = percentile(latencies, 0.90)
+	stats.P95 = percentile(latencies, 0.95)
+	stats.P99 = percentile(latencies, 0.99)
+	stats.P999 = percentile(latencies, 0.999)
+	stats.Histogram = histogram(latencies, defaultHistogramBuckets)
+
+	var variance float64
+	for _, l := range latencies {
+		d := float64(l - stats.Mean)
+		variance += d * d
+	}
+	stats.StdDev = time.Duration(math.Sqrt(variance / float64(len(latencies))))
+
+	q1, q3 := percentile(latencies, 0.25), percentile(latencies, 0.75)
+	fence := q3 + (q3-q1)*3/2
+	for _, inv := range invocations {
+		if inv.Err == nil && inv.Latency > fence {
+			stats.Outliers = append(stats.Outliers, inv.Index)
+		}
+	}
+	return stats
+}
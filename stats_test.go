@@ -0,0 +1,70 @@
+package sls
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func durations(ms ...int) []time.Duration {
+	d := make([]time.Duration, len(ms))
+	for i, m := range ms {
+		d[i] = time.Duration(m) * time.Millisecond
+	}
+	return d
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := durations(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	tests := []struct {
+		sorted []time.Duration
+		p      float64
+		want   time.Duration
+	}{
+		{nil, 0.5, 0},
+		{durations(7), 0.99, 7 * time.Millisecond},
+		{sorted, 0, 1 * time.Millisecond},
+		{sorted, 0.5, 5 * time.Millisecond},
+		{sorted, 0.9, 9 * time.Millisecond},
+		{sorted, 0.95, 10 * time.Millisecond},
+		{sorted, 0.999, 10 * time.Millisecond},
+		{sorted, 1, 10 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		got := percentile(tt.sorted, tt.p)
+		if got != tt.want {
+			t.Errorf("percentile(%v, %v) = %v, want %v", tt.sorted, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		sorted []time.Duration
+		n      int
+		want   []HistogramBucket
+	}{
+		{nil, 4, nil},
+		{durations(1, 2), 0, nil},
+		{durations(3, 3, 3), 4, []HistogramBucket{{From: 3 * ms, To: 3 * ms, Count: 3}}},
+		{durations(0, 1, 2, 3, 4, 5, 6, 7, 8), 4, []HistogramBucket{
+			{From: 0, To: 2 * ms, Count: 2},
+			{From: 2 * ms, To: 4 * ms, Count: 2},
+			{From: 4 * ms, To: 6 * ms, Count: 2},
+			{From: 6 * ms, To: 8 * ms, Count: 3},
+		}},
+		// the last bucket reaches the largest latency when the range doesn't split evenly
+		{durations(10, 11, 20), 3, []HistogramBucket{
+			{From: 10 * ms, To: 13333333 * time.Nanosecond, Count: 2},
+			{From: 13333333 * time.Nanosecond, To: 16666666 * time.Nanosecond, Count: 0},
+			{From: 16666666 * time.Nanosecond, To: 20 * ms, Count: 1},
+		}},
+	}
+	for _, tt := range tests {
+		got := histogram(tt.sorted, tt.n)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("histogram(%v, %d) = %v, want %v", tt.sorted, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package sls
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const streamChunkSize = 32 * 1024
+
+type StreamResult struct {
+	StatusCode int
+	// TimeToFirstByte is until the first chunk of the body, Duration until the end of the stream
+	TimeToFirstByte time.Duration
+	Duration        time.Duration
+	Bytes           int64
+	Chunks          int
+}
+
+// InvokeStream posts the payload to the function's url and passes the body to onChunk as it arrives, which is how
+// functions with invokeMode RESPONSE_STREAM respond, a chunk is only valid during the call and the url has to allow
+// unauthenticated requests
+func (w *Wrapper) InvokeStream(ctx context.Context, key string, payload []byte, onChunk func([]byte)) (*StreamResult, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	info, err := w.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	url := ""
+	for _, e := range info.Endpoints {
+		if e.Function == key {
+			url = e.URL
+		}
+	}
+	if url == "" {
+		return nil, fmt.Errorf("function %s has no function url", key)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &StreamResult{StatusCode: resp.StatusCode}
+	if resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("invoking %s returned %s: %s", key, resp.Status, bytes.TrimSpace(body))
+	}
+	buf := make([]byte, streamChunkSize)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if result.Chunks == 0 {
+				result.TimeToFirstByte = time.Since(start)
+			}
+			result.Chunks++
+			result.Bytes += int64(n)
+			if onChunk != nil {
+				onChunk(buf[:n])
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	result.Duration = time.Since(start)
+	return result, nil
+}
@@ -0,0 +1,150 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// terraformTypes maps the CloudFormation types whose physical ids are terraform import ids to terraform types,
+// resources with composite import ids, such as api gateway methods, aren't exported
+var terraformTypes = map[string]string{
+	"AWS::Lambda::Function":           "aws_lambda_function",
+	"AWS::Lambda::EventSourceMapping": "aws_lambda_event_source_mapping",
+	"AWS::Lambda::Url":                "aws_lambda_function_url",
+	"AWS::Logs::LogGroup":             "aws_cloudwatch_log_group",
+	"AWS::IAM::Role":                  "aws_iam_role",
+	"AWS::S3::Bucket":                 "aws_s3_bucket",
+	"AWS::S3::BucketPolicy":           "aws_s3_bucket_policy",
+	"AWS::ApiGateway::RestApi":        "aws_api_gateway_rest_api",
+	"AWS::ApiGatewayV2::Api":          "aws_apigatewayv2_api",
+	"AWS::DynamoDB::Table":            "aws_dynamodb_table",
+	"AWS::SQS::Queue":                 "aws_sqs_queue",
+	"AWS::SNS::Topic":                 "aws_sns_topic",
+	"AWS::Events::Rule":               "aws_cloudwatch_event_rule",
+}
+
+type TerraformResource struct {
+	// Type and Name make up the resource's terraform address
+	Type string
+	Name string
+	// ID is the import id
+	ID                 string
+	LogicalID          string
+	CloudFormationType string
+}
+
+func (r TerraformResource) Address() string {
+	return r.Type + "." + r.Name
+}
+
+// TerraformExport describes a deployed stack's resources for terraform or opentofu to import, Skipped lists the
+// logical ids of resources that can't be imported by their physical id
+type TerraformExport struct {
+	StackName string
+	Region    string
+	Resources []TerraformResource
+	Skipped   []string
+}
+
+// terraformName turns a logical id such as HelloLambdaFunction into hello_lambda_function
+func terraformName(logicalID string) string {
+	var b strings.Builder
+	runes := []rune(logicalID)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// terraformID converts physical ids that differ from the import id
+func terraformID(cfnType string, physicalID string) string {
+	switch cfnType {
+	case "AWS::Lambda::Url":
+		// the physical id is the function's arn
+		if i := strings.Index(physicalID, ":function:"); i >= 0 {
+			return physicalID[i+len(":function:"):]
+		}
+	case "AWS::Events::Rule":
+		// rules on custom buses are <bus>|<rule>
+		return strings.Replace(physicalID, "|", "/", 1)
+	}
+	return physicalID
+}
+
+// TerraformExport describes the deployed stack's resources as terraform imports, so the stack can be adopted by
+// terraform or opentofu
+func (w *Wrapper) TerraformExport(ctx context.Context) (*TerraformExport, error) {
+	err := w.requireAWS("terraform export")
+	if err != nil {
+		return nil, err
+	}
+	resources, err := w.stackResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &TerraformExport{StackName: w.StackName(), Region: w.Region()}
+	for _, r := range resources {
+		tfType, ok := terraformTypes[r.ResourceType]
+		if !ok || r.PhysicalResourceId == "" {
+			export.Skipped = append(export.Skipped, r.LogicalResourceId)
+			continue
+		}
+		export.Resources = append(export.Resources, TerraformResource{
+			Type:               tfType,
+			Name:               terraformName(r.LogicalResourceId),
+			ID:                 terraformID(r.ResourceType, r.PhysicalResourceId),
+			LogicalID:          r.LogicalResourceId,
+			CloudFormationType: r.ResourceType,
+		})
+	}
+	sort.Slice(export.Resources, func(i, j int) bool {
+		return export.Resources[i].Address() < export.Resources[j].Address()
+	})
+	sort.Strings(export.Skipped)
+	return export, nil
+}
+
+// WriteImportBlocks writes import blocks, terraform plan -generate-config-out writes the matching configuration
+func (e *TerraformExport) WriteImportBlocks(out io.Writer) error {
+	_, err := fmt.Fprintf(out, "# resources of stack %s in %s\n", e.StackName, e.Region)
+	if err != nil {
+		return err
+	}
+	for _, r := range e.Resources {
+		_, err = fmt.Fprintf(out, "\n# %s %s\nimport {\n  to = %s\n  id = %q\n}\n", r.CloudFormationType, r.LogicalID, r.Address(), r.ID)
+		if err != nil {
+			return err
+		}
+	}
+	if len(e.Skipped) > 0 {
+		_, err = fmt.Fprintf(out, "\n# not exported: %s\n", strings.Join(e.Skipped, ", "))
+	}
+	return err
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// WriteImportScript writes a shell script importing the resources one by one, binary is terraform or tofu
+func (e *TerraformExport) WriteImportScript(out io.Writer, binary string) error {
+	_, err := fmt.Fprintf(out, "#!/bin/sh\n# resources of stack %s in %s\nset -e\n", e.StackName, e.Region)
+	if err != nil {
+		return err
+	}
+	for _, r := range e.Resources {
+		_, err = fmt.Fprintf(out, "%s import %s %s\n", binary, shellQuote(r.Address()), shellQuote(r.ID))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+)
+
+const defaultProbeMaxConcurrency = 1000
+
+type ConcurrencyProbeOptions struct {
+	// the concurrency starts at Start and doubles up to Max, the payload has to keep the function busy long enough
+	// for the invocations of a round to overlap
+	Start int
+	Max   int
+}
+
+type ConcurrencyProbe struct {
+	// Ceiling is the most invocations that ran at once, Throttled is set when the probe hit the limit before Max
+	Ceiling   int
+	Throttled bool
+	// Reserved is the function's reserved concurrency, when set, Unreserved is what the account leaves to the rest
+	Reserved     *int
+	AccountLimit int
+	Unreserved   int
+}
+
+func (w *Wrapper) concurrencyLimits(ctx context.Context, name string, probe *ConcurrencyProbe) error {
+	var function struct {
+		ReservedConcurrentExecutions *int
+	}
+	err := w.awsCmd(ctx, &function, "lambda", "get-function-concurrency", "--function-name", name)
+	if err != nil {
+		return err
+	}
+	var account struct {
+		AccountLimit struct {
+			ConcurrentExecutions           int
+			UnreservedConcurrentExecutions int
+		}
+	}
+	err = w.awsCmd(ctx, &account, "lambda", "get-account-settings")
+	if err != nil {
+		return err
+	}
+	probe.Reserved = function.ReservedConcurrentExecutions
+	probe.AccountLimit = account.AccountLimit.ConcurrentExecutions
+	probe.Unreserved = account.AccountLimit.UnreservedConcurrentExecutions
+	return nil
+}
+
+// ProbeConcurrency fires rounds of simultaneous invocations of growing size until lambda throttles them, reporting
+// the effective concurrency ceiling along with the configured limits it should match, the rounds are invoked in process
+// without retries so every throttle is counted instead of being retried into extra latency
+func (w *Wrapper) ProbeConcurrency(ctx context.Context, key string, payload []byte, opts ConcurrencyProbeOptions) (*ConcurrencyProbe, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	if opts.Start < 1 {
+		opts.Start = 1
+	}
+	if opts.Max < 1 {
+		opts.Max = defaultProbeMaxConcurrency
+	}
+
+	probe := &ConcurrencyProbe{}
+	err := w.concurrencyLimits(ctx, w.functionName(key), probe)
+	if err != nil {
+		return nil, err
+	}
+
+	for c := opts.Start; ; c *= 2 {
+		if c > opts.Max {
+			c = opts.Max
+		}
+		result, err := w.InvokeN(ctx, key, payload, c, c)
+		if err != nil {
+			return nil, err
+		}
+
+		ok, throttled := 0, 0
+		for _, inv := range result.Invocations {
+			switch inv.Category {
+			case "":
+				ok++
+			case ErrorThrottle:
+				throttled++
+			default:
+				return nil, fmt.Errorf("invocation %d of round %d failed: %v", inv.Index, c, inv.Err)
+			}
+		}
+		if ok > probe.Ceiling {
+			probe.Ceiling = ok
+		}
+		if throttled > 0 {
+			probe.Throttled = true
+			return probe, nil
+		}
+		if c == opts.Max {
+			return probe, nil
+		}
+	}
+}
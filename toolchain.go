@@ -0,0 +1,131 @@
+package sls
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type toolchain struct {
+	command     string
+	versionArgs []string
+	minVersion  string
+}
+
+var toolchains = map[string][]toolchain{
+	"java8":  {{command: "mvn", versionArgs: []string{"--version"}, minVersion: "3.0"}},
+	"java11": {{command: "mvn", versionArgs: []string{"--version"}, minVersion: "3.5"}},
+	"csharp": {{command: "dotnet", versionArgs: []string{"--version"}, minVersion: "2.1"}},
+	"golang": {{command: "go", versionArgs: []string{"version"}, minVersion: "1.11"}},
+	"nodejs": {{command: "npm", versionArgs: []string{"--version"}, minVersion: "5.0"}},
+	"python": {{command: "pip", versionArgs: []string{"--version"}, minVersion: "9.0"}},
+}
+
+var versionRe = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// CheckToolchains verifies the build tools of every runtime present in the stack, all problems are reported at once
+func (w *Wrapper) CheckToolchains() error {
+	dirs, err := w.discoverPlatforms()
+	if err != nil {
+		return err
+	}
+	return w.checkToolchains(dirs)
+}
+
+func (w *Wrapper) checkToolchains(dirs []platformDir) error {
+	required := make(map[string]toolchain)
+	users := make(map[string][]string)
+	require := func(user string, tc toolchain) {
+		if cur, ok := required[tc.command]; !ok || compareVersions(tc.minVersion, cur.minVersion) > 0 {
+			required[tc.command] = tc
+		}
+		users[tc.command] = append(users[tc.command], user)
+	}
+
+	for _, d := range dirs {
+		for _, tc := range toolchains[d.platform] {
+			require(d.name, tc)
+		}
+	}
+	for name, layer := range w.stack.Layers {
+		layerPath := filepath.Join(w.yamlDirPath, layer.Path)
+		if layer.Path != "" && fileExists(filepath.Join(layerPath, "nodejs", "package.json")) {
+			require("layer:"+name, toolchains["nodejs"][0])
+		}
+		if layer.Path != "" && fileExists(filepath.Join(layerPath, "python", "requirements.txt")) {
+			require("layer:"+name, toolchains["python"][0])
+		}
+	}
+
+	commands := make([]string, 0, len(required))
+	for command := range required {
+		commands = append(commands, command)
+	}
+	sort.Strings(commands)
+
+	var problems []string
+	for _, command := range commands {
+		tc := required[command]
+		sort.Strings(users[command])
+		err := w.checkToolchain(tc, users[command][0])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s (needed by %s): %v", command, strings.Join(users[command], ", "), err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("missing toolchain:\n  " + strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+func (w *Wrapper) checkToolchain(tc toolchain, runtime string) error {
+	env := w.buildEnv(runtime)
+	cmdPath, err := lookPathEnv(tc.command, env)
+	if err != nil {
+		return errors.New("not installed")
+	}
+
+	cmd := exec.Command(cmdPath, tc.versionArgs...)
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to get version: %v", err)
+	}
+
+	version := versionRe.FindString(string(out))
+	if version == "" {
+		return fmt.Errorf("couldn't parse version from %q", strings.TrimSpace(string(out)))
+	}
+	if compareVersions(version, tc.minVersion) < 0 {
+		return fmt.Errorf("version %s is older than the minimum %s", version, tc.minVersion)
+	}
+	return nil
+}
+
+func compareVersions(a string, b string) int {
+	pa := strings.Split(a, ".")
+	pb := strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i])
+		}
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,163 @@
+package sls
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	traceTimeout      = time.Minute
+	tracePollInterval = 2 * time.Second
+)
+
+type TraceSegment struct {
+	Name      string
+	Namespace string
+	Start     time.Time
+	Duration  time.Duration
+	Error     bool
+	// Subsegments of the function's segment include Initialization, Invocation and Overhead
+	Subsegments []TraceSegment
+}
+
+// Trace breaks down an invocation, Downstream are the aws and remote calls the function made
+type Trace struct {
+	ID         string
+	Duration   time.Duration
+	Init       time.Duration
+	Invoke     time.Duration
+	Overhead   time.Duration
+	Downstream []TraceSegment
+	Segments   []TraceSegment
+}
+
+type segmentDocument struct {
+	Name        string
+	Origin      string
+	Namespace   string
+	StartTime   float64 `json:"start_time"`
+	EndTime     float64 `json:"end_time"`
+	Error       bool
+	Fault       bool
+	Subsegments []segmentDocument
+}
+
+func xrayTime(t float64) time.Time {
+	sec, frac := math.Modf(t)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+}
+
+func (d segmentDocument) segment() TraceSegment {
+	s := TraceSegment{
+		Name:      d.Name,
+		Namespace: d.Namespace,
+		Start:     xrayTime(d.StartTime),
+		Duration:  time.Duration((d.EndTime - d.StartTime) * float64(time.Second)),
+		Error:     d.Error || d.Fault,
+	}
+	for _, sub := range d.Subsegments {
+		s.Subsegments = append(s.Subsegments, sub.segment())
+	}
+	return s
+}
+
+// downstream collects the subsegments of calls leaving the function
+func downstream(segments []TraceSegment) []TraceSegment {
+	var calls []TraceSegment
+	for _, s := range segments {
+		if s.Namespace == "aws" || s.Namespace == "remote" {
+			calls = append(calls, s)
+			continue
+		}
+		calls = append(calls, downstream(s.Subsegments)...)
+	}
+	return calls
+}
+
+func parseTrace(id string, duration float64, documents []string) (*Trace, error) {
+	trace := &Trace{ID: id, Duration: time.Duration(duration * float64(time.Second))}
+	for _, doc := range documents {
+		var d segmentDocument
+		err := json.Unmarshal([]byte(doc), &d)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse segment of trace %s: %v", id, err)
+		}
+		s := d.segment()
+		trace.Segments = append(trace.Segments, s)
+		if d.Origin != "AWS::Lambda::Function" {
+			continue
+		}
+		for _, sub := range s.Subsegments {
+			switch sub.Name {
+			case "Initialization":
+				trace.Init = sub.Duration
+			case "Invocation":
+				trace.Invoke = sub.Duration
+			case "Overhead":
+				trace.Overhead = sub.Duration
+			}
+		}
+		trace.Downstream = append(trace.Downstream, downstream(s.Subsegments)...)
+	}
+	return trace, nil
+}
+
+// tracingEnabled checks the deployed function, whose tracing comes from the provider's or the function's config
+func (w *Wrapper) tracingEnabled(ctx context.Context, key string) error {
+	config, err := w.getFunctionConfiguration(ctx, w.functionName(key))
+	if err != nil {
+		return err
+	}
+	if config.TracingConfig.Mode != "Active" {
+		return fmt.Errorf("function %s is not traced, enable tracing.lambda in %s", key, w.configName)
+	}
+	return nil
+}
+
+// InvocationTrace fetches the x-ray trace of an invocation of the function by the trace id of its Report, x-ray
+// takes a few seconds to make a trace available so it's polled for up to a minute
+func (w *Wrapper) InvocationTrace(ctx context.Context, key string, traceID string) (*Trace, error) {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	err := w.tracingEnabled(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if traceID == "" {
+		return nil, fmt.Errorf("invocation of %s has no trace id", key)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, traceTimeout)
+	defer cancel()
+	for {
+		var resp struct {
+			Traces []struct {
+				Id       string
+				Duration float64
+				Segments []struct {
+					Document string
+				}
+			}
+		}
+		err := w.awsCmd(ctx, &resp, "xray", "batch-get-traces", "--trace-ids", traceID)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("trace %s of %s not available after %v", traceID, key, traceTimeout)
+		}
+		if err != nil {
+			return nil, err
+		}
+		// the function's segment arrives after lambda's
+		if len(resp.Traces) > 0 && len(resp.Traces[0].Segments) > 1 {
+			var documents []string
+			for _, s := range resp.Traces[0].Segments {
+				documents = append(documents, s.Document)
+			}
+			return parseTrace(resp.Traces[0].Id, resp.Traces[0].Duration, documents)
+		}
+		sleepContext(ctx, tracePollInterval)
+	}
+}
@@ -0,0 +1,263 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var variantTagRe = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// functionVariant is a copy of a function deployed under <function>-<tag>, apply adjusts the copy's settings
+type functionVariant struct {
+	tag   string
+	apply func(f yaml.MapSlice) yaml.MapSlice
+}
+
+func variantTag(s string) string {
+	return variantTagRe.ReplaceAllString(s, "")
+}
+
+func mapSliceIndex(m yaml.MapSlice, key string) int {
+	for i, item := range m {
+		if k, ok := item.Key.(string); ok && k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func setMapSliceItem(m yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	if i := mapSliceIndex(m, key); i >= 0 {
+		m[i].Value = value
+		return m
+	}
+	return append(m, yaml.MapItem{Key: key, Value: value})
+}
+
+// variantsConfigName is the config generated for the variants deployed under the wrapper's suffix, the framework
+// only accepts configs placed in the service dir
+func (w *Wrapper) variantsConfigName() string {
+	return "serverless-" + w.suffix + ".yml"
+}
+
+// withVariants writes a config in which the function is replaced by its variants and returns a wrapper deploying it
+// under the same suffix, the variants' keys are returned in order
+func (w *Wrapper) withVariants(function string, variants []functionVariant) (*Wrapper, []string, error) {
+	if _, ok := w.stack.Functions[function]; !ok {
+		return nil, nil, fmt.Errorf("function %s is not defined in %s", function, w.configName)
+	}
+
+	yamlData, err := ioutil.ReadFile(w.configPath())
+	if err != nil {
+		return nil, nil, err
+	}
+	var config yaml.MapSlice
+	err = yaml.Unmarshal(yamlData, &config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	i := mapSliceIndex(config, "functions")
+	if i < 0 {
+		return nil, nil, fmt.Errorf("no functions in %s", w.configName)
+	}
+	functions, ok := config[i].Value.(yaml.MapSlice)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected functions section in %s", w.configName)
+	}
+	j := mapSliceIndex(functions, function)
+	base, _ := functions[j].Value.(yaml.MapSlice)
+
+	var keys []string
+	var items yaml.MapSlice
+	for _, v := range variants {
+		key := function + "-" + variantTag(v.tag)
+		if mapSliceIndex(functions, key) >= 0 || mapSliceIndex(items, key) >= 0 {
+			return nil, nil, fmt.Errorf("variant %s of %s collides with an existing function", key, function)
+		}
+
+		f := append(yaml.MapSlice{}, base...)
+		if n := mapSliceIndex(f, "name"); n >= 0 {
+			if name, ok := f[n].Value.(string); ok {
+				f[n].Value = name + "-" + variantTag(v.tag)
+			}
+		}
+		f = v.apply(f)
+
+		keys = append(keys, key)
+		items = append(items, yaml.MapItem{Key: key, Value: f})
+	}
+
+	replaced := append(yaml.MapSlice{}, functions[:j]...)
+	replaced = append(replaced, items...)
+	replaced = append(replaced, functions[j+1:]...)
+	config[i].Value = replaced
+
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	name := w.variantsConfigName()
+	err = ioutil.WriteFile(filepath.Join(w.yamlDirPath, name), out, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clone := *w
+	clone.configName = name
+	other, err := clone.WithSuffix(w.suffix)
+	if err != nil {
+		os.Remove(filepath.Join(w.yamlDirPath, name))
+		return nil, nil, err
+	}
+	return other, keys, nil
+}
+
+// removeGeneratedConfig deletes a generated variants or LocalStack config once its stack is gone
+func (w *Wrapper) removeGeneratedConfig() error {
+	if w.configName != w.variantsConfigName() && w.configName != w.localStackConfigName() {
+		return nil
+	}
+	err := os.Remove(w.configPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// runtimeArtifact points a package artifact built under the base runtime's dir at the variant runtime's dir
+func (w *Wrapper) runtimeArtifact(artifact string, baseRuntime string, runtime string) string {
+	parts := strings.SplitN(path.Clean(filepath.ToSlash(artifact)), "/", 2)
+	if artifact == "" || len(parts) != 2 || (parts[0] != baseRuntime && parts[0] != runtimePlatform(baseRuntime)) {
+		return artifact
+	}
+	dir := runtime
+	if !fileExists(filepath.Join(w.yamlDirPath, runtime)) {
+		dir = runtimePlatform(runtime)
+	}
+	return dir + "/" + parts[1]
+}
+
+// RuntimeMatrix is one copy of a function deployed per runtime, Functions maps each runtime to the deployed function
+// name and Keys to its key in the generated config
+type RuntimeMatrix struct {
+	Wrapper   *Wrapper
+	Result    *DeployResult
+	Functions map[string]string
+	Keys      map[string]string
+}
+
+// DeployRuntimeMatrix deploys the function once per runtime under this wrapper's suffix, each runtime's sources
+// are built from the dir named after the runtime or its platform
+func (w *Wrapper) DeployRuntimeMatrix(ctx context.Context, function string, runtimes []string) (*RuntimeMatrix, error) {
+	f, ok := w.stack.Functions[function]
+	if !ok {
+		return nil, fmt.Errorf("function %s is not defined in %s", function, w.configName)
+	}
+	if len(runtimes) == 0 {
+		return nil, fmt.Errorf("no runtimes given for %s", function)
+	}
+
+	baseRuntime := w.functionRuntime(f)
+	var variants []functionVariant
+	for _, runtime := range runtimes {
+		if runtimePlatform(runtime) == "" {
+			return nil, fmt.Errorf("unsupported runtime %s", runtime)
+		}
+		runtime := runtime
+		artifact := w.runtimeArtifact(f.Package.Artifact, baseRuntime, runtime)
+		variants = append(variants, functionVariant{tag: runtime, apply: func(f yaml.MapSlice) yaml.MapSlice {
+			f = setMapSliceItem(f, "runtime", runtime)
+			if artifact == "" {
+				return f
+			}
+			var pkg yaml.MapSlice
+			if i := mapSliceIndex(f, "package"); i >= 0 {
+				pkg, _ = f[i].Value.(yaml.MapSlice)
+			}
+			pkg = setMapSliceItem(append(yaml.MapSlice{}, pkg...), "artifact", artifact)
+			return setMapSliceItem(f, "package", pkg)
+		}})
+	}
+
+	matrix, keys, err := w.withVariants(function, variants)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &RuntimeMatrix{Wrapper: matrix, Functions: make(map[string]string), Keys: make(map[string]string)}
+	for i, runtime := range runtimes {
+		m.Keys[runtime] = keys[i]
+		m.Functions[runtime] = matrix.functionName(keys[i])
+	}
+
+	m.Result, err = matrix.DeployStackContext(ctx)
+	if err != nil {
+		return m, err
+	}
+	for runtime, key := range m.Keys {
+		if fr, ok := m.Result.Functions[key]; ok && fr.Name != "" {
+			m.Functions[runtime] = fr.Name
+		}
+	}
+	return m, nil
+}
+
+// MemoryVariant is one copy of a swept function, Key is its key in the generated config and Name the deployed name
+type MemoryVariant struct {
+	MemorySize int
+	Key        string
+	Name       string
+}
+
+type MemorySweep struct {
+	Wrapper  *Wrapper
+	Result   *DeployResult
+	Variants []MemoryVariant
+}
+
+// DeployMemorySweep deploys the function once per memory size (in MB) under this wrapper's suffix
+func (w *Wrapper) DeployMemorySweep(ctx context.Context, function string, memorySizes []int) (*MemorySweep, error) {
+	if len(memorySizes) == 0 {
+		return nil, fmt.Errorf("no memory sizes given for %s", function)
+	}
+
+	var variants []functionVariant
+	for _, size := range memorySizes {
+		if size < 128 || size > 10240 {
+			return nil, fmt.Errorf("memory size %d MB is out of lambda's range", size)
+		}
+		size := size
+		variants = append(variants, functionVariant{tag: fmt.Sprintf("%dmb", size), apply: func(f yaml.MapSlice) yaml.MapSlice {
+			return setMapSliceItem(f, "memorySize", size)
+		}})
+	}
+
+	sweep, keys, err := w.withVariants(function, variants)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &MemorySweep{Wrapper: sweep}
+	for i, size := range memorySizes {
+		s.Variants = append(s.Variants, MemoryVariant{MemorySize: size, Key: keys[i], Name: sweep.functionName(keys[i])})
+	}
+
+	s.Result, err = sweep.DeployStackContext(ctx)
+	if err != nil {
+		return s, err
+	}
+	for i, v := range s.Variants {
+		if fr, ok := s.Result.Functions[v.Key]; ok && fr.Name != "" {
+			s.Variants[i].Name = fr.Name
+		}
+	}
+	return s, nil
+}
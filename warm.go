@@ -0,0 +1,59 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const maxWarmRounds = 10
+
+// warmRound invokes the function concurrency times at once and returns how many of the invocations were cold starts
+func (w *Wrapper) warmRound(ctx context.Context, name string, payload []byte, concurrency int) (int, error) {
+	samples := make([]ColdStart, concurrency)
+	var wg sync.WaitGroup
+	for i := range samples {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			samples[i] = w.timedInvoke(ctx, name, payload)
+		}(i)
+	}
+	wg.Wait()
+
+	cold := 0
+	for _, s := range samples {
+		if s.Err != nil {
+			return 0, s.Err
+		}
+		if s.Cold {
+			cold++
+		}
+	}
+	return cold, nil
+}
+
+// Warm invokes the function with its probe payload in rounds of concurrency simultaneous invocations until a round
+// has no cold starts, meaning that many instances are warm
+func (w *Wrapper) Warm(ctx context.Context, key string, concurrency int) error {
+	if _, ok := w.stack.Functions[key]; !ok {
+		return fmt.Errorf("function %s is not defined in %s", key, w.configName)
+	}
+	if concurrency < 1 {
+		return fmt.Errorf("invalid concurrency %d", concurrency)
+	}
+
+	name := w.functionName(key)
+	cold := 0
+	for round := 0; round < maxWarmRounds; round++ {
+		var err error
+		cold, err = w.warmRound(ctx, name, w.probePayload(key), concurrency)
+		if err != nil {
+			return fmt.Errorf("failed to warm %s: %v", name, err)
+		}
+		if cold == 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s still had %d cold starts out of %d after %d rounds", name, cold, concurrency, maxWarmRounds)
+}
@@ -2,6 +2,7 @@ package sls
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"gopkg.in/yaml.v2"
@@ -9,37 +10,96 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
-	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
 	YamlName   = "serverless.yml"
 	slsRetries = 10
+
+	killGracePeriod = 10 * time.Second
+
+	defaultRegion = "us-east-1"
+	defaultStage  = "dev"
 )
 
 type FunctionMeta struct {
-	Name        string `yaml:"name"`
-	Handler     string `yaml:"handler"`
-	Description string `yaml:"description"`
-	Runtime     string `yaml:"runtime"`
-	MemorySize  string `yaml:"memorySize"`
+	Name        string                   `yaml:"name"`
+	Handler     string                   `yaml:"handler"`
+	Description string                   `yaml:"description"`
+	Runtime     string                   `yaml:"runtime"`
+	MemorySize  string                   `yaml:"memorySize"`
+	Package     PackageMeta              `yaml:"package"`
+	Image       string                   `yaml:"image"`
+	Events      []map[string]interface{} `yaml:"events"`
+}
+
+type PackageMeta struct {
+	Artifact string `yaml:"artifact"`
+	// Patterns select the packaged files, Exclude and Include are the options they replaced in newer framework versions
+	Patterns []string `yaml:"patterns"`
+	Exclude  []string `yaml:"exclude"`
+	Include  []string `yaml:"include"`
 }
 
 type Functions map[string]FunctionMeta
 
+type LayerMeta struct {
+	Name               string      `yaml:"name"`
+	Path               string      `yaml:"path"`
+	Description        string      `yaml:"description"`
+	CompatibleRuntimes []string    `yaml:"compatibleRuntimes"`
+	Package            PackageMeta `yaml:"package"`
+}
+
+type Layers map[string]LayerMeta
+
+// Plugins are the config's plugins, listed directly or under modules
+type Plugins []string
+
+func (p *Plugins) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		*p = list
+		return nil
+	}
+	var modules struct {
+		Modules []string `yaml:"modules"`
+	}
+	if err := unmarshal(&modules); err != nil {
+		return err
+	}
+	*p = modules.Modules
+	return nil
+}
+
 type ServiceStack struct {
 	StackId  string `yaml:"service"`
 	Provider struct {
 		Name    string `yaml:"name"`
 		Project string `yaml:"project"`
 		Stage   string `yaml:"stage"`
+		Runtime string `yaml:"runtime"`
+		Region  string `yaml:"region"`
+		// Namespace and Docker are the knative provider's cluster namespace and image registry
+		Namespace string `yaml:"namespace"`
+		Docker    struct {
+			Registry string `yaml:"registry"`
+			Username string `yaml:"username"`
+			Password string `yaml:"password"`
+		} `yaml:"docker"`
+		// Credentials is the credentials file of the tencent and aliyun providers
+		Credentials string `yaml:"credentials"`
 	}
 
+	Package   PackageMeta `yaml:"package"`
+	Plugins   Plugins
 	Functions Functions
+	Layers    Layers
 }
 
 type Wrapper struct {
@@ -48,22 +108,102 @@ type Wrapper struct {
 	yamlDirPath string
 	stack       *ServiceStack
 	suffix      string
-	Opts        map[string]string
+	configName  string
+	// promotedFrom is the stage a Promote wrapper deploys the artifacts of
+	promotedFrom string
+	infoCache    *infoCache
+	processMu    *sync.Mutex
+	offline      *Offline
+	dev          *DevSession
+	Opts         map[string]string
+	BuildEnvs    map[string]BuildEnv
+	// CI disables the framework's interactive setup and telemetry and fails sls runs that prompt anyway
+	CI bool
+	// ArtifactsDir redirects build outputs out of the source tree, relative paths are resolved against the yaml dir,
+	// package.artifact references relative to the source tree are rewritten to point into it for sls
+	ArtifactsDir string
+	Reproducible bool
+	// BundleNode bundles every node function into a zip of its own with esbuild or webpack, which is deployed as the
+	// function's package.artifact
+	BundleNode bool
+	// SkipUnchanged skips the build and deploy when the sources didn't change since the last deploy of this suffix
+	SkipUnchanged bool
+	// SelectiveDeploy updates only the functions whose sources changed since the last deploy of this suffix, as long
+	// as the config didn't change
+	SelectiveDeploy bool
+	// MaxParallelBuilds limits the concurrent builders, zero means the number of CPUs
+	MaxParallelBuilds int
+	// BuildTimeouts limits each builder by its runtime dir name, BuildTimeout applies to the rest
+	BuildTimeout  time.Duration
+	BuildTimeouts map[string]time.Duration
+	// PrebuiltArtifacts maps function keys to zips or binaries built elsewhere, their runtimes' builders are skipped
+	PrebuiltArtifacts map[string]string
+	// ImageRepository is the repository image functions are pushed to, ECR unless the provider has its own registry
+	ImageRepository string
+	Cache           ArtifactCache
+	// DeployPackageDir deploys a directory produced by Package instead of building the stack
+	DeployPackageDir string
+	// Force passes --force to deploys, redeploying even when the framework detects no changes
+	Force bool
+	// SmokeAfterDeploy invokes every function once after deploying, with ProbePayloads or ProbePayload as the event
+	SmokeAfterDeploy bool
+	ProbePayload     []byte
+	ProbePayloads    map[string][]byte
+	// InvokeLogs requests the function's log tail with every invoke
+	InvokeLogs  bool
+	LocalInvoke LocalInvokeOptions
+	// VerifyRemoval makes removing the stack wait until CloudFormation reports the stack deleted
+	VerifyRemoval  bool
+	RemovalTimeout time.Duration
+	// ForceRemoval empties the stack's buckets and retains resources that fail to delete when removal gets stuck
+	ForceRemoval bool
+	// Registry records every deploy, defaults to a json file in the project's state dir
+	Registry Registry
+	// Locker keeps concurrent processes from deploying or removing the same stack at once
+	Locker Locker
+	// KeepFailedStack runs deploys once without cleaning up after them, dumping the failed stack's events instead
+	KeepFailedStack bool
+	// KeepVersions prunes all but the last KeepVersions versions of every function after a deploy, zero keeps them all
+	KeepVersions int
+	// ResourcePreflight packages the stack before deploying and fails when it exceeds cloudformation's resource limit
+	ResourcePreflight bool
+	// OnStackEvent receives the CloudFormation events of deploys as they happen
+	OnStackEvent func(StackEvent)
+	// DeployTimeout bounds a whole deploy, builds and retries included
+	DeployTimeout time.Duration
+	// RollbackOnFailure cleans up after a failed deploy, removing a stack that failed to create and finishing a stuck
+	// update rollback
+	RollbackOnFailure bool
+	// Sink receives the invocations of InvokeN and Load as they complete
+	Sink ResultSink
+	// AutoInstallPlugins installs the config's plugins missing from node_modules before deploying, only those in
+	// PluginAllowList when it's set and never those in PluginDenyList
+	AutoInstallPlugins bool
+	PluginAllowList    []string
+	PluginDenyList     []string
+	// LocalStackEndpoint points sls and the aws cli at LocalStack, it's set by WithLocalStack
+	LocalStackEndpoint string
 }
 
 func New(provider string, yamlDirPath string) (*Wrapper, error) {
+	return NewWithSuffix(provider, yamlDirPath, strconv.FormatInt(time.Now().UnixNano(), 10))
+}
+
+func NewWithSuffix(provider string, yamlDirPath string, suffix string) (*Wrapper, error) {
+	return newWrapper(provider, yamlDirPath, YamlName, suffix)
+}
+
+func newWrapper(provider string, yamlDirPath string, configName string, suffix string) (*Wrapper, error) {
 	path, err := getSLSPath()
 	if err != nil {
 		return nil, errors.New("serverless framework is not installed")
 	}
 
-	stack, err := ParseConfig(provider, yamlDirPath)
+	stack, err := parseConfigFile(provider, filepath.Join(yamlDirPath, configName))
 	if err != nil {
 		return nil, err
 	}
 
-	suffix := strconv.FormatInt(time.Now().UnixNano(), 10)
-
 	functions := make(map[string]FunctionMeta)
 	for k, v := range stack.Functions {
 		v.Name = strings.Replace(v.Name, "${opt:suffix}", suffix, -1)
@@ -72,7 +212,15 @@ func New(provider string, yamlDirPath string) (*Wrapper, error) {
 
 	stack.Functions = functions
 
-	return &Wrapper{provider: provider, slsPath: path, yamlDirPath: yamlDirPath, stack: stack, suffix: suffix, Opts: make(map[string]string)}, nil
+	layers := make(map[string]LayerMeta)
+	for k, v := range stack.Layers {
+		v.Name = strings.Replace(v.Name, "${opt:suffix}", suffix, -1)
+		layers[k] = v
+	}
+
+	stack.Layers = layers
+
+	return &Wrapper{provider: provider, slsPath: path, yamlDirPath: yamlDirPath, stack: stack, suffix: suffix, configName: configName, Opts: make(map[string]string), BuildEnvs: make(map[string]BuildEnv), infoCache: newInfoCache(), processMu: &sync.Mutex{}, BuildTimeouts: make(map[string]time.Duration), PrebuiltArtifacts: make(map[string]string)}, nil
 }
 
 func getSLSPath() (string, error) {
@@ -80,7 +228,11 @@ func getSLSPath() (string, error) {
 }
 
 func ParseConfig(provider string, yamlDirPath string) (*ServiceStack, error) {
-	yamlData, err := ioutil.ReadFile(filepath.Join(yamlDirPath, YamlName))
+	return parseConfigFile(provider, filepath.Join(yamlDirPath, YamlName))
+}
+
+func parseConfigFile(provider string, configPath string) (*ServiceStack, error) {
+	yamlData, err := ioutil.ReadFile(configPath)
 	if err != nil {
 		return nil, err
 	}
@@ -102,6 +254,14 @@ func (w *Wrapper) ListFunctionsFromYaml() Functions {
 	return w.stack.Functions
 }
 
+func (w *Wrapper) configPath() string {
+	return filepath.Join(w.yamlDirPath, w.configName)
+}
+
+func (w *Wrapper) Suffix() string {
+	return w.suffix
+}
+
 func (w *Wrapper) StackId() string {
 	return strings.Replace(w.stack.StackId, "-${opt:suffix}", "", -1)
 }
@@ -114,154 +274,315 @@ func (w *Wrapper) Stage() string {
 	return w.stack.Provider.Stage
 }
 
-func (w *Wrapper) execCmd(env []string, dir string, command string, cmdArgs ...string) (string, error) {
+func (w *Wrapper) Region() string {
+	if region, ok := w.Opts["region"]; ok {
+		return region
+	}
+	if w.stack.Provider.Region != "" && !strings.Contains(w.stack.Provider.Region, "${") {
+		return w.stack.Provider.Region
+	}
+	if p, ok := w.Provider().(RegionProvider); ok {
+		return p.DefaultRegion()
+	}
+	return defaultRegion
+}
+
+func (w *Wrapper) execCmd(ctx context.Context, env []string, dir string, command string, cmdArgs ...string) (string, error) {
+	return w.execCmdOutput(ctx, env, dir, os.Stdout, os.Stderr, command, cmdArgs...)
+}
+
+func (w *Wrapper) execCmdOutput(ctx context.Context, env []string, dir string, stdoutOut io.Writer, stderrOut io.Writer, command string, cmdArgs ...string) (string, error) {
+	return w.execCmdIO(ctx, env, dir, nil, stdoutOut, stderrOut, command, cmdArgs...)
+}
+
+func (w *Wrapper) execCmdIO(ctx context.Context, env []string, dir string, stdin io.Reader, stdoutOut io.Writer, stderrOut io.Writer, command string, cmdArgs ...string) (string, error) {
 	var stdoutBuf, stderrBuf bytes.Buffer
 	var errStdout, errStderr error
 
 	cwd := dir
+	env = w.cmdEnv(command, env)
 
-	cmd := exec.Command(command, cmdArgs...)
+	cmdPath, err := lookPathEnv(command, env)
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command(cmdPath, cmdArgs...)
 	cmd.Dir = cwd
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd.Stdin = stdin
+	setProcessGroup(cmd)
 
 	stdoutIn, _ := cmd.StdoutPipe()
 	stderrIn, _ := cmd.StderrPipe()
 
-	stdout := io.MultiWriter(os.Stdout, &stdoutBuf)
-	stderr := io.MultiWriter(os.Stderr, &stderrBuf)
-	err := cmd.Start()
+	stdout := io.MultiWriter(stdoutOut, &stdoutBuf)
+	stderr := io.MultiWriter(stderrOut, &stderrBuf)
+	err = cmd.Start()
 	if err != nil {
 		return "", err
 	}
 
+	done := make(chan struct{})
+	defer close(done)
 	go func() {
+		select {
+		case <-ctx.Done():
+			// let sls release its state before it's killed
+			interruptProcessGroup(cmd)
+			select {
+			case <-time.After(killGracePeriod):
+				killProcessGroup(cmd)
+			case <-done:
+			}
+		case <-done:
+		}
+	}()
+
+	var copies sync.WaitGroup
+	copies.Add(2)
+	go func() {
+		defer copies.Done()
 		_, errStdout = io.Copy(stdout, stdoutIn)
 	}()
 
 	go func() {
+		defer copies.Done()
 		_, errStderr = io.Copy(stderr, stderrIn)
 	}()
 
+	copies.Wait()
 	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return strings.TrimSpace(stdoutBuf.String()), ctx.Err()
+	}
 	if errStdout != nil || errStderr != nil {
 		return "", errors.New("failed to capture stdout or stderr")
 	}
 	return strings.TrimSpace(stdoutBuf.String()), err
 }
 
-func (w *Wrapper) execSlsCmd(funcDir string, slsCmd ...string) (string, error) {
+func (w *Wrapper) execSlsCmd(ctx context.Context, funcDir string, slsCmd ...string) (string, error) {
+	return w.execSlsCmdRetries(ctx, funcDir, slsRetries, slsCmd...)
+}
+
+// slsArgs appends the wrapper's options to an sls command
+func (w *Wrapper) slsArgs(slsCmd ...string) []string {
 	slsCmd = append(slsCmd, "--suffix")
 	slsCmd = append(slsCmd, w.suffix)
 
+	if w.configName != YamlName {
+		slsCmd = append(slsCmd, "--config", w.configName)
+	}
+
+	if w.ArtifactsDir != "" {
+		slsCmd = append(slsCmd, "--"+artifactsOpt)
+		slsCmd = append(slsCmd, w.artifactsRoot())
+	}
+
 	for opt, optVal := range w.Opts {
 		slsCmd = append(slsCmd, "--"+opt)
 		slsCmd = append(slsCmd, optVal)
 	}
+	return slsCmd
+}
 
-	retries := slsRetries
-	resp, err := w.execCmd([]string{}, funcDir, "sls", slsCmd...)
-	for err != nil && retries > 0 {
-		resp, err = w.execCmd([]string{}, funcDir, "sls", slsCmd...)
-		time.Sleep(5 * time.Second)
+func (w *Wrapper) execSlsCmdRetries(ctx context.Context, funcDir string, retries int, slsCmd ...string) (string, error) {
+	slsCmd = w.slsArgs(slsCmd...)
+	run := func() (string, error) {
+		if w.CI {
+			return w.execSlsCmdCI(ctx, funcDir, slsCmd...)
+		}
+		return w.execCmd(ctx, []string{}, funcDir, "sls", slsCmd...)
+	}
+
+	resp, err := run()
+	for err != nil && retries > 0 && ctx.Err() == nil {
+		if _, prompted := err.(*PromptError); prompted {
+			break
+		}
+		resp, err = run()
+		sleepContext(ctx, 5*time.Second)
 		retries--
 	}
 	return resp, err
 }
 
+func sleepContext(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
+
+// Deprecated: DeployStack can't be canceled and discards the result, use DeployStackContext.
 func (w *Wrapper) DeployStack() error {
+	_, err := w.DeployStackContext(context.Background())
+	return err
+}
+
+func (w *Wrapper) DeployStackContext(ctx context.Context) (*DeployResult, error) {
+	phases := &phaseTimer{}
+	if w.DeployTimeout == 0 {
+		return w.deployStack(ctx, phases)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.DeployTimeout)
+	defer cancel()
+	result, err := w.deployStack(ctx, phases)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return result, &DeadlineError{StackName: w.StackName(), Timeout: w.DeployTimeout, Phases: phases.stop(), Err: err}
+	}
+	return result, err
+}
 
-	err := w.buildJava("java8")
+func (w *Wrapper) deployStack(ctx context.Context, phases *phaseTimer) (*DeployResult, error) {
+	err := w.checkAWSOptions()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = w.buildJava("java11")
+	// the credential checks of some providers look for their plugin, install it first
+	if w.AutoInstallPlugins {
+		phases.start("plugins")
+		unlockDir := w.lockDir()
+		err = w.installMissingPlugins(ctx)
+		unlockDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = w.Provider().CheckCredentials(ctx, w)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = w.buildCsharp()
+
+	phases.start("lock")
+	unlock, err := w.lockStack(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer unlock()
+
+	if w.DeployPackageDir != "" {
+		phases.start("deploy")
+		return w.deployPackage(ctx, w.DeployPackageDir)
+	}
+
+	if w.SelectiveDeploy && !w.Force {
+		phases.start("deploy")
+		unlockDir := w.lockDir()
+		result, ok, err := w.deployChangedFunctions(ctx)
+		unlockDir()
+		if err != nil || ok {
+			return result, err
+		}
+	}
+
+	phases.start("build")
+	var sourceHash string
+	if w.SkipUnchanged && !w.Force {
+		unlockDir := w.lockDir()
+		state, hash, fresh, err := w.deployIsFresh()
+		unlockDir()
+		if err != nil {
+			return nil, err
+		}
+		if fresh && state.Result != nil {
+			fmt.Fprintf(os.Stderr, "stack %s is up to date, skipping deploy\n", w.StackId())
+			return state.Result, nil
+		}
+		sourceHash = hash
 	}
-	err = w.buildGolang()
+
+	pkg, err := w.buildPackage(ctx, phases)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer pkg.remove()
+	results := pkg.results
+	phases.start("deploy")
+	out, err := pkg.deployer.execDeploy(ctx, append(w.deployArgs(), "--package", pkg.dir)...)
+	if err != nil {
+		if w.KeepFailedStack {
+			return nil, err
+		}
+		if w.RollbackOnFailure && ctx.Err() == nil {
+			return nil, w.cleanupFailedDeploy(ctx, err)
+		}
+		return nil, err
 	}
-	_, err = w.execSlsCmd(w.yamlDirPath, "deploy", "--no-aws-s3-accelerate")
-	return err
-}
 
-func (w *Wrapper) buildJava(version string) error {
-	javaPath, javaInStack, err := w.platformPath(version)
+	err = w.writeManifest(ctx, results)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if !javaInStack {
-		return nil
+	result := w.deployResult(ctx, out, results)
+	err = w.recordDeployment(result)
+	if err != nil {
+		return nil, err
 	}
-	_, err = w.execCmd([]string{}, javaPath, "mvn", "package")
-	if err != nil && strings.HasPrefix(err.Error(), "WARNING") {
-		return nil
+	w.pruneAfterDeploy(ctx)
+	if w.SmokeAfterDeploy {
+		phases.start("smoke")
+		_, err = w.SmokeTest(ctx)
+		if err != nil {
+			return result, err
+		}
 	}
-	return err
+	if w.SkipUnchanged || w.SelectiveDeploy {
+		state := &deployState{SourceHash: sourceHash, Artifacts: results, Result: result, DeployedAt: time.Now()}
+		state.ConfigHash, state.FunctionHashes, err = w.deployHashes()
+		if err != nil {
+			return nil, err
+		}
+		err = w.saveDeployState(state)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
 }
 
+// Deprecated: RemoveStack can't be canceled, use RemoveStackContext.
 func (w *Wrapper) RemoveStack() error {
-	_, err := w.execSlsCmd(w.yamlDirPath, "remove")
-	return err
+	return w.RemoveStackContext(context.Background())
 }
 
-func (w *Wrapper) ListFunction() error {
-	_, err := w.execSlsCmd(w.yamlDirPath, "deploy", "list", "functions")
+func (w *Wrapper) RemoveStackContext(ctx context.Context) error {
+	unlock, err := w.lockStack(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-	return err
+	return w.removeStack(ctx)
 }
 
-func (w *Wrapper) buildCsharp() error {
-	csharpPath, csharpInStack, err := w.platformPath("csharp")
+func (w *Wrapper) removeStack(ctx context.Context) error {
+	_, err := w.execSlsCmd(ctx, w.yamlDirPath, "remove")
+	w.cacheInfo(nil)
+	err = w.Provider().Cleanup(ctx, w, err)
 	if err != nil {
 		return err
 	}
-	if !csharpInStack {
-		return nil
-	}
-	_, err = w.execCmd([]string{}, csharpPath, "dotnet", "restore")
+	err = w.registry().Forget(w.StackName(), w.Region())
 	if err != nil {
 		return err
 	}
-	_, err = w.execCmd([]string{},
-		csharpPath,
-		"dotnet",
-		"lambda",
-		"package",
-		"--configuration",
-		"release",
-		"--framework",
-		"netcoreapp2.1",
-		"--output-package",
-		"./deploy.zip")
-	return err
-}
-
-func (w *Wrapper) platformPath(platform string) (string, bool, error) {
-	srcPath := path.Join(w.yamlDirPath, platform)
-	_, err := os.Stat(srcPath)
-	if err != nil && os.IsNotExist(err) {
-		return "", false, nil
-	}
+	err = w.removeDeployState()
 	if err != nil {
-		return "", false, err
+		return err
 	}
-	return srcPath, true, nil
+	return w.removeGeneratedConfig()
 }
 
-func (w *Wrapper) buildGolang() error {
-	golangPath, goInStack, err := w.platformPath("golang")
-	if err != nil {
-		return err
-	}
-	if !goInStack {
-		return nil
-	}
-	env := []string{"GOOS=linux", "GO111MODULE=on"}
-	_, err = w.execCmd(env, golangPath, "go", "build", "-ldflags", "-s", "-ldflags", "-w", "-o", "bin/hello", "main.go")
+// Deprecated: ListFunction discards the listing, use ListFunctions.
+func (w *Wrapper) ListFunction() error {
+	_, err := w.execSlsCmd(context.Background(), w.yamlDirPath, "deploy", "list", "functions")
+
 	return err
 }
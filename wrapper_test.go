@@ -0,0 +1,48 @@
+package sls
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// newTestWrapper makes a wrapper of a service written to dir with the given files, sls only has to be found on the
+// PATH since nothing runs it
+func newTestWrapper(t *testing.T, dir string, files map[string]string) *Wrapper {
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(p), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(p, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bin := filepath.Join(dir, ".bin")
+	err := os.MkdirAll(bin, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sls := "sls"
+	if runtime.GOOS == "windows" {
+		sls += ".exe"
+	}
+	err = ioutil.WriteFile(filepath.Join(bin, sls), nil, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	os.Setenv("PATH", bin+string(os.PathListSeparator)+path)
+
+	w, err := NewWithSuffix("aws", dir, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
@@ -0,0 +1,133 @@
+package sls
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const bootstrapName = "bootstrap"
+
+type artifactFile struct {
+	src  string
+	name string
+	mode os.FileMode
+}
+
+func dirArtifactFiles(srcDir string, prefix string) ([]artifactFile, error) {
+	var files []artifactFile
+	err := filepath.Walk(srcDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(srcDir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, artifactFile{src: p, name: path.Join(prefix, filepath.ToSlash(rel)), mode: info.Mode()})
+		return nil
+	})
+	return files, err
+}
+
+// dirSourceFiles lists the files of srcDir like dirArtifactFiles, leaving out the build dir when it's inside srcDir
+func dirSourceFiles(srcDir string, prefix string, buildDir string) ([]artifactFile, error) {
+	srcDir, err := filepath.Abs(srcDir)
+	if err != nil {
+		return nil, err
+	}
+	all, err := dirArtifactFiles(srcDir, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var files []artifactFile
+	for _, f := range all {
+		if !strings.HasPrefix(f.src, buildDir+string(filepath.Separator)) {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// zipArtifact writes the entries sorted by name, a non zero modTime replaces the files' timestamps
+func zipArtifact(dst string, files []artifactFile, modTime time.Time) error {
+	files = append([]artifactFile{}, files...)
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+
+	err := os.MkdirAll(filepath.Dir(dst), 0755)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+	for _, f := range files {
+		if filepath.Clean(f.src) == filepath.Clean(dst) {
+			continue
+		}
+		err = addZipEntry(zw, f, modTime)
+		if err != nil {
+			zw.Close()
+			return err
+		}
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func addZipEntry(zw *zip.Writer, f artifactFile, modTime time.Time) error {
+	src, err := os.Open(f.src)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	mode := f.mode
+	if mode == 0 {
+		mode = info.Mode()
+	}
+	// the lambda custom runtime executes bootstrap directly, so it has to keep the exec bit
+	if path.Base(f.name) == bootstrapName {
+		mode |= 0755
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = strings.TrimPrefix(f.name, "/")
+	header.Method = zip.Deflate
+	header.SetMode(mode)
+	if !modTime.IsZero() {
+		header.Modified = modTime
+	}
+
+	entry, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, src)
+	return err
+}
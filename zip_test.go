@@ -0,0 +1,82 @@
+package sls
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]os.FileMode) {
+	for name, mode := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(p), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(p, []byte(name), mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the umask may have dropped bits of the mode
+		err = os.Chmod(p, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestZipArtifactModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows files have no unix modes")
+	}
+	tmp, err := ioutil.TempDir("", "sls-zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	src := filepath.Join(tmp, "src")
+	writeFiles(t, src, map[string]os.FileMode{
+		"bootstrap":     0644,
+		"bin/run.sh":    0755,
+		"assets/a.json": 0600,
+	})
+
+	files, err := dirArtifactFiles(src, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifact := filepath.Join(tmp, "deploy.zip")
+	err = zipArtifact(artifact, files, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.OpenReader(artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	want := map[string]os.FileMode{
+		// the custom runtime executes bootstrap, which keeps the exec bit whatever its mode on disk
+		"bootstrap":     0755,
+		"bin/run.sh":    0755,
+		"assets/a.json": 0600,
+	}
+	if len(r.File) != len(want) {
+		t.Fatalf("expected %d entries, found %d", len(want), len(r.File))
+	}
+	for _, f := range r.File {
+		mode, ok := want[f.Name]
+		if !ok {
+			t.Errorf("unexpected entry %s", f.Name)
+			continue
+		}
+		if f.Mode().Perm() != mode {
+			t.Errorf("%s: expected mode %v, got %v", f.Name, mode, f.Mode().Perm())
+		}
+	}
+}